@@ -0,0 +1,122 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package diagram implements export of directed graphs as text-based
+// diagrams in the Mermaid and PlantUML languages, which can be
+// embedded in Markdown and other documentation.
+package diagram
+
+import (
+	"container/list"
+	"fmt"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Direction is the direction in which the edges of a diagram point.
+type Direction string
+
+// The supported directions of a diagram.
+const (
+	TopBottom Direction = "TB"
+	LeftRight Direction = "LR"
+)
+
+// Options control how a diagram is written. A nil *Options is the
+// same as the zero value.
+type Options struct {
+	// Direction of the diagram. If empty, the diagram is drawn
+	// from top to bottom.
+	Direction Direction
+
+	// VertexLabel is called to get the label of each vertex. If
+	// nil, the string representation of the vertex is used.
+	VertexLabel func(vertex directed.Vertex) string
+
+	// Components will, if true, group each strongly connected
+	// component with more than one vertex in a subgraph.
+	Components bool
+
+	// Path is a path to highlight, given as a list of vertices
+	// such as the one returned by FindShortestPath. The vertices
+	// of the path and the edges between consecutive vertices are
+	// highlighted.
+	Path *list.List
+}
+
+// diagram is the information common to all diagram languages.
+type diagram struct {
+	direction  Direction
+	ids        map[directed.Vertex]string
+	labels     map[directed.Vertex]string
+	components []*directed.Graph
+	grouped    map[directed.Vertex]bool
+	onPath     map[directed.Vertex]bool
+	pathEdges  map[directed.Edge]bool
+}
+
+// newDiagram will collect the information needed to write a diagram
+// of the graph. The vertices are given IDs based on the order they
+// were added to the graph, since the vertices themselves can be
+// arbitrary values.
+func newDiagram(graph *directed.Graph, opts *Options) (*diagram, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	d := &diagram{
+		direction: opts.Direction,
+		ids:       make(map[directed.Vertex]string, graph.Order()),
+		labels:    make(map[directed.Vertex]string, graph.Order()),
+		grouped:   make(map[directed.Vertex]bool),
+		onPath:    make(map[directed.Vertex]bool),
+		pathEdges: make(map[directed.Edge]bool),
+	}
+	switch d.direction {
+	case "":
+		d.direction = TopBottom
+	case TopBottom, LeftRight:
+	default:
+		return nil, fmt.Errorf("diagram: unsupported direction %q", d.direction)
+	}
+
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		d.ids[vertex] = fmt.Sprintf("n%d", len(d.ids))
+		if opts.VertexLabel != nil {
+			d.labels[vertex] = opts.VertexLabel(vertex)
+		} else {
+			d.labels[vertex] = fmt.Sprint(vertex)
+		}
+		return nil
+	})
+
+	if opts.Components {
+		graph.DoCycles(func(component *directed.Graph) error {
+			d.components = append(d.components, component)
+			component.DoVertices(func(vertex directed.Vertex) error {
+				d.grouped[vertex] = true
+				return nil
+			})
+			return nil
+		})
+	}
+
+	if opts.Path != nil {
+		var previous directed.Vertex
+		for elem := opts.Path.Front(); elem != nil; elem = elem.Next() {
+			if !graph.HasVertex(elem.Value) {
+				return nil, fmt.Errorf("diagram: path vertex %v not in graph", elem.Value)
+			}
+			if elem != opts.Path.Front() {
+				if !graph.HasEdge(previous, elem.Value) {
+					return nil, fmt.Errorf("diagram: no edge %v -> %v in graph", previous, elem.Value)
+				}
+				d.pathEdges[directed.Edge{Source: previous, Target: elem.Value}] = true
+			}
+			d.onPath[elem.Value] = true
+			previous = elem.Value
+		}
+	}
+	return d, nil
+}
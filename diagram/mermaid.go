@@ -0,0 +1,105 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package diagram
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// highlightStyle is the style used for highlighted vertices and
+// edges in Mermaid diagrams.
+const highlightStyle = "stroke:#d62728,stroke-width:3px"
+
+// mermaidQuote will return 's' as a quoted Mermaid label. Characters
+// that have a special meaning are written as entity codes, and
+// newlines as line breaks.
+func mermaidQuote(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			builder.WriteString("#quot;")
+		case '#':
+			builder.WriteString("#35;")
+		case '<':
+			builder.WriteString("#lt;")
+		case '>':
+			builder.WriteString("#gt;")
+		case '\n':
+			builder.WriteString("<br>")
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// WriteMermaid will write the graph to 'out' as a Mermaid flowchart.
+func WriteMermaid(out io.Writer, graph *directed.Graph, opts *Options) error {
+	d, err := newDiagram(graph, opts)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(out)
+	fmt.Fprintf(buf, "flowchart %s\n", d.direction)
+
+	writeVertex := func(indent string, vertex directed.Vertex) {
+		fmt.Fprintf(buf, "%s%s[%s]\n", indent, d.ids[vertex], mermaidQuote(d.labels[vertex]))
+	}
+	for number, component := range d.components {
+		fmt.Fprintf(buf, "    subgraph scc%d [\"Component %d\"]\n", number, number+1)
+		graph.DoVertices(func(vertex directed.Vertex) error {
+			if component.HasVertex(vertex) {
+				writeVertex("        ", vertex)
+			}
+			return nil
+		})
+		buf.WriteString("    end\n")
+	}
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		if !d.grouped[vertex] {
+			writeVertex("    ", vertex)
+		}
+		return nil
+	})
+
+	// Mermaid identifies edges by their position, so the positions
+	// of the highlighted edges are recorded.
+	var highlighted []string
+	count := 0
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		fmt.Fprintf(buf, "    %s --> %s\n", d.ids[source], d.ids[target])
+		if d.pathEdges[directed.Edge{Source: source, Target: target}] {
+			highlighted = append(highlighted, strconv.Itoa(count))
+		}
+		count++
+		return nil
+	})
+
+	if len(d.onPath) > 0 {
+		var ids []string
+		graph.DoVertices(func(vertex directed.Vertex) error {
+			if d.onPath[vertex] {
+				ids = append(ids, d.ids[vertex])
+			}
+			return nil
+		})
+		fmt.Fprintf(buf, "    classDef path %s\n", highlightStyle)
+		fmt.Fprintf(buf, "    class %s path\n", strings.Join(ids, ","))
+	}
+	if len(highlighted) > 0 {
+		fmt.Fprintf(buf, "    linkStyle %s %s\n", strings.Join(highlighted, ","), highlightStyle)
+	}
+	return buf.Flush()
+}
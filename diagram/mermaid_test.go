@@ -0,0 +1,94 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package diagram
+
+import (
+	"bytes"
+	"container/list"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// exampleGraph will return a graph with a cycle between b and c.
+func exampleGraph() *directed.Graph {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+	graph.AddEdge("c", "b")
+	graph.AddEdge("c", `say "hi" #1`)
+	return graph
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteMermaid(&out, exampleGraph(), nil); err != nil {
+		t.Fatalf("WriteMermaid failed: %v", err)
+	}
+	expected := `flowchart TB
+    n0["a"]
+    n1["b"]
+    n2["c"]
+    n3["say #quot;hi#quot; #35;1"]
+    n0 --> n1
+    n1 --> n2
+    n2 --> n1
+    n2 --> n3
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteMermaidOptions(t *testing.T) {
+	graph := exampleGraph()
+	path, err := graph.FindShortestPath("a", "c")
+	if err != nil {
+		t.Fatalf("FindShortestPath failed: %v", err)
+	}
+	var out bytes.Buffer
+	opts := &Options{Direction: LeftRight, Components: true, Path: path}
+	if err := WriteMermaid(&out, graph, opts); err != nil {
+		t.Fatalf("WriteMermaid failed: %v", err)
+	}
+	expected := `flowchart LR
+    subgraph scc0 ["Component 1"]
+        n1["b"]
+        n2["c"]
+    end
+    n0["a"]
+    n3["say #quot;hi#quot; #35;1"]
+    n0 --> n1
+    n1 --> n2
+    n2 --> n1
+    n2 --> n3
+    classDef path stroke:#d62728,stroke-width:3px
+    class n0,n1,n2 path
+    linkStyle 0,1 stroke:#d62728,stroke-width:3px
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestOptionErrors(t *testing.T) {
+	graph := exampleGraph()
+	var out bytes.Buffer
+	if err := WriteMermaid(&out, graph, &Options{Direction: "XY"}); err == nil {
+		t.Errorf("Expected error for bad direction")
+	}
+
+	path := list.New()
+	path.PushBack("a")
+	path.PushBack("c")
+	if err := WritePlantUML(&out, graph, &Options{Path: path}); err == nil {
+		t.Errorf("Expected error for path with missing edge")
+	}
+	path.PushBack("x")
+	if err := WriteMermaid(&out, graph, &Options{Path: path}); err == nil {
+		t.Errorf("Expected error for path with missing vertex")
+	}
+}
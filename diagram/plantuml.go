@@ -0,0 +1,89 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package diagram
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// plantQuote will return 's' as a quoted PlantUML string. Double
+// quotes are written as Unicode escapes, and newlines and backslashes
+// are escaped.
+func plantQuote(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			builder.WriteString("<U+0022>")
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\n':
+			builder.WriteString(`\n`)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// WritePlantUML will write the graph to 'out' as a PlantUML diagram,
+// where each vertex is a rectangle and strongly connected components
+// are grouped in packages.
+func WritePlantUML(out io.Writer, graph *directed.Graph, opts *Options) error {
+	d, err := newDiagram(graph, opts)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(out)
+	buf.WriteString("@startuml\n")
+	if d.direction == LeftRight {
+		buf.WriteString("left to right direction\n")
+	} else {
+		buf.WriteString("top to bottom direction\n")
+	}
+
+	writeVertex := func(indent string, vertex directed.Vertex) {
+		fmt.Fprintf(buf, "%srectangle %s as %s", indent, plantQuote(d.labels[vertex]), d.ids[vertex])
+		if d.onPath[vertex] {
+			buf.WriteString(" #line:red;line.bold")
+		}
+		buf.WriteByte('\n')
+	}
+	for number, component := range d.components {
+		fmt.Fprintf(buf, "package \"Component %d\" {\n", number+1)
+		graph.DoVertices(func(vertex directed.Vertex) error {
+			if component.HasVertex(vertex) {
+				writeVertex("  ", vertex)
+			}
+			return nil
+		})
+		buf.WriteString("}\n")
+	}
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		if !d.grouped[vertex] {
+			writeVertex("", vertex)
+		}
+		return nil
+	})
+
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		arrow := "-->"
+		if d.pathEdges[directed.Edge{Source: source, Target: target}] {
+			arrow = "-[#red,bold]->"
+		}
+		fmt.Fprintf(buf, "%s %s %s\n", d.ids[source], arrow, d.ids[target])
+		return nil
+	})
+	buf.WriteString("@enduml\n")
+	return buf.Flush()
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func TestWritePlantUML(t *testing.T) {
+	graph := exampleGraph()
+	path, _ := graph.FindShortestPath("b", `say "hi" #1`)
+	opts := &Options{
+		Direction:  LeftRight,
+		Components: true,
+		Path:       path,
+		VertexLabel: func(vertex directed.Vertex) string {
+			return fmt.Sprintf("Vertex\n%v", vertex)
+		},
+	}
+	var out bytes.Buffer
+	if err := WritePlantUML(&out, graph, opts); err != nil {
+		t.Fatalf("WritePlantUML failed: %v", err)
+	}
+	expected := `@startuml
+left to right direction
+package "Component 1" {
+  rectangle "Vertex\nb" as n1 #line:red;line.bold
+  rectangle "Vertex\nc" as n2 #line:red;line.bold
+}
+rectangle "Vertex\na" as n0
+rectangle "Vertex\nsay <U+0022>hi<U+0022> #1" as n3 #line:red;line.bold
+n0 --> n1
+n1 -[#red,bold]-> n2
+n2 --> n1
+n2 -[#red,bold]-> n3
+@enduml
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := WritePlantUML(&out, graph, nil); err != nil {
+		t.Fatalf("WritePlantUML failed: %v", err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("top to bottom direction\n")) {
+		t.Errorf("Expected top to bottom direction:\n%s", out.String())
+	}
+}
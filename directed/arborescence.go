@@ -0,0 +1,267 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import "fmt"
+
+// indexedEdge is an edge between vertices given by their index in a
+// vertex table.
+type indexedEdge struct {
+	source, target int
+	weight         float64
+}
+
+// skewNode is a node in a skew heap of edges. Skew heaps can be
+// merged in amortized logarithmic time, and the 'delta' field allows
+// the weight of all edges in a heap to be adjusted in constant time.
+type skewNode struct {
+	edge        indexedEdge
+	delta       float64
+	left, right *skewNode
+}
+
+// push will apply any pending weight adjustment of the node to the
+// edge of the node and pass it on to the children.
+func (node *skewNode) push() {
+	node.edge.weight += node.delta
+	if node.left != nil {
+		node.left.delta += node.delta
+	}
+	if node.right != nil {
+		node.right.delta += node.delta
+	}
+	node.delta = 0
+}
+
+// mergeSkew will merge two skew heaps and return the merged heap.
+func mergeSkew(a, b *skewNode) *skewNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	a.push()
+	b.push()
+	if a.edge.weight > b.edge.weight {
+		a, b = b, a
+	}
+	a.left, a.right = mergeSkew(b, a.right), a.left
+	return a
+}
+
+// rollbackSet is a union-find structure without path compression
+// where unions can be undone, which is necessary to expand the
+// contracted cycles once the arborescence is found.
+type rollbackSet struct {
+	parent  []int
+	history [][2]int
+}
+
+func newRollbackSet(size int) *rollbackSet {
+	set := &rollbackSet{parent: make([]int, size)}
+	for i := range set.parent {
+		set.parent[i] = -1
+	}
+	return set
+}
+
+// find will return the representative of the set that 'x' is member
+// of.
+func (set *rollbackSet) find(x int) int {
+	for set.parent[x] >= 0 {
+		x = set.parent[x]
+	}
+	return x
+}
+
+// union will merge the sets of 'x' and 'y' and return true if they
+// were in different sets, false otherwise.
+func (set *rollbackSet) union(x, y int) bool {
+	x, y = set.find(x), set.find(y)
+	if x == y {
+		return false
+	}
+	// The representative stores the negated size of the set.
+	if set.parent[x] > set.parent[y] {
+		x, y = y, x
+	}
+	set.history = append(set.history, [2]int{x, set.parent[x]}, [2]int{y, set.parent[y]})
+	set.parent[x] += set.parent[y]
+	set.parent[y] = x
+	return true
+}
+
+// rollback will undo all unions done after 'time', which is the
+// length of the history at the time.
+func (set *rollbackSet) rollback(time int) {
+	for i := len(set.history) - 1; i >= time; i-- {
+		set.parent[set.history[i][0]] = set.history[i][1]
+	}
+	set.history = set.history[:time]
+}
+
+// contraction records a cycle that was contracted into a single
+// vertex, used to expand the cycle afterwards.
+type contraction struct {
+	vertex, time int
+	edges        []indexedEdge
+}
+
+// MinimumArborescence will compute a minimum spanning arborescence of
+// the graph rooted at 'root', that is, a spanning tree where all
+// edges are directed away from the root and the sum of the edge
+// weights, given by 'weight', is minimal.
+//
+// The arborescence is returned as a new graph containing all the
+// vertices of the graph, together with the total weight of the
+// arborescence. If some vertex of the graph is not reachable from the
+// root, no arborescence exists and an error is returned.
+//
+// The implementation is Tarjan's version of the Chu-Liu/Edmonds
+// algorithm, which uses mergeable heaps to pick the cheapest in-edge
+// of each vertex and has complexity O(|E| log |V|).
+func (graph *Graph) MinimumArborescence(root Vertex, weight WeightFunc) (*Graph, float64, error) {
+	if !graph.HasVertex(root) {
+		return nil, 0, fmt.Errorf("root %v not in graph", root)
+	}
+	if err := graph.checkReachable(root); err != nil {
+		return nil, 0, err
+	}
+
+	vertices, index := graph.indexVertices()
+	count := len(vertices)
+	rootIndex := index[root]
+
+	// Each vertex has a heap of its in-edges. Self-loops and edges
+	// into the root can never be part of the arborescence.
+	heaps := make([]*skewNode, count)
+	graph.DoEdges(func(source, target Vertex) error {
+		if source != target && target != root {
+			edge := indexedEdge{index[source], index[target], weight(source, target)}
+			heaps[edge.target] = mergeSkew(heaps[edge.target], &skewNode{edge: edge})
+		}
+		return nil
+	})
+
+	sets := newRollbackSet(count)
+	seen := make([]int, count)
+	for i := range seen {
+		seen[i] = -1
+	}
+	seen[rootIndex] = rootIndex
+	queue := make([]indexedEdge, count)
+	path := make([]int, count)
+	incoming := make([]indexedEdge, count)
+	var contractions []contraction
+	total := 0.0
+
+	for start := range vertices {
+		current, depth := start, 0
+		for seen[current] < 0 {
+			if heaps[current] == nil {
+				return nil, 0, fmt.Errorf("vertex %v not reachable from root %v",
+					vertices[current], root)
+			}
+			// Pick the cheapest in-edge and reduce the weight
+			// of the remaining in-edges by the same amount.
+			heaps[current].push()
+			edge := heaps[current].edge
+			heaps[current].delta -= edge.weight
+			heaps[current].push()
+			heaps[current] = mergeSkew(heaps[current].left, heaps[current].right)
+
+			queue[depth], path[depth] = edge, current
+			depth++
+			seen[current] = start
+			total += edge.weight
+			current = sets.find(edge.source)
+			if seen[current] == start {
+				// Found a cycle, so contract it into a
+				// single vertex.
+				var heap *skewNode
+				end, time := depth, len(sets.history)
+				for {
+					depth--
+					other := path[depth]
+					heap = mergeSkew(heap, heaps[other])
+					if !sets.union(current, other) {
+						break
+					}
+				}
+				current = sets.find(current)
+				heaps[current] = heap
+				seen[current] = -1
+				edges := append([]indexedEdge(nil), queue[depth:end]...)
+				contractions = append(contractions, contraction{current, time, edges})
+			}
+		}
+		for _, edge := range queue[:depth] {
+			incoming[sets.find(edge.target)] = edge
+		}
+	}
+
+	// Expand the contracted cycles in reverse order. All edges of
+	// the cycle are used except the one replaced by the edge
+	// entering the cycle.
+	for i := len(contractions) - 1; i >= 0; i-- {
+		cycle := contractions[i]
+		sets.rollback(cycle.time)
+		entering := incoming[cycle.vertex]
+		for _, edge := range cycle.edges {
+			incoming[sets.find(edge.target)] = edge
+		}
+		incoming[sets.find(entering.target)] = entering
+	}
+
+	tree := New()
+	for i, vertex := range vertices {
+		tree.AddVertex(vertex)
+		if i != rootIndex {
+			tree.AddEdge(vertices[incoming[i].source], vertex)
+		}
+	}
+	return tree, total, nil
+}
+
+// indexVertices will number the vertices of the graph from zero and
+// return a table of the vertices together with a map from each
+// vertex to its index.
+func (graph *Graph) indexVertices() ([]Vertex, map[Vertex]int) {
+	vertices := make([]Vertex, 0, graph.Order())
+	index := make(map[Vertex]int, graph.Order())
+	graph.DoVertices(func(vertex Vertex) error {
+		index[vertex] = len(vertices)
+		vertices = append(vertices, vertex)
+		return nil
+	})
+	return vertices, index
+}
+
+// checkReachable will check that all vertices of the graph are
+// reachable from 'root' and return an error naming one of the
+// unreachable vertices otherwise.
+func (graph *Graph) checkReachable(root Vertex) error {
+	reached := map[Vertex]bool{root: true}
+	stack := []Vertex{root}
+	for len(stack) > 0 {
+		vertex := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		graph.DoOutEdges(vertex, func(source, target Vertex) error {
+			if !reached[target] {
+				reached[target] = true
+				stack = append(stack, target)
+			}
+			return nil
+		})
+	}
+	return graph.DoVertices(func(vertex Vertex) error {
+		if !reached[vertex] {
+			return fmt.Errorf("vertex %v not reachable from root %v", vertex, root)
+		}
+		return nil
+	})
+}
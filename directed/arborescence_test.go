@@ -0,0 +1,143 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkArborescence checks that 'tree' is an arborescence of 'graph'
+// rooted at 'root' with the given total weight.
+func checkArborescence(t *testing.T, graph, tree *Graph, root Vertex, weight WeightFunc, total float64) {
+	if tree.Order() != graph.Order() || tree.Size() != graph.Order()-1 {
+		t.Fatalf("Not a spanning tree (%d vertices and %d edges)", tree.Order(), tree.Size())
+	}
+	sum := 0.0
+	indegree := make(map[Vertex]int)
+	tree.DoEdges(func(source, target Vertex) error {
+		if !graph.HasEdge(source, target) {
+			t.Errorf("Edge %v -> %v not in graph", source, target)
+		}
+		indegree[target]++
+		sum += weight(source, target)
+		return nil
+	})
+	tree.DoVertices(func(vertex Vertex) error {
+		if vertex != root && indegree[vertex] != 1 {
+			t.Errorf("Vertex %v has in-degree %d", vertex, indegree[vertex])
+		}
+		return nil
+	})
+	if indegree[root] != 0 {
+		t.Errorf("Root %v has in-edges", root)
+	}
+	if math.Abs(sum-total) > 1e-9 {
+		t.Errorf("Total weight %v do not match sum of edges %v", total, sum)
+	}
+}
+
+func TestMinimumArborescence(t *testing.T) {
+	weights := map[Edge]float64{
+		{"r", "a"}: 10, {"r", "b"}: 12, {"a", "b"}: 1, {"b", "a"}: 1,
+		{"b", "c"}: 5, {"c", "a"}: 1, {"c", "d"}: 2, {"d", "c"}: 1,
+		{"r", "d"}: 20, {"a", "a"}: 0,
+	}
+	weight := func(source, target Vertex) float64 {
+		return weights[Edge{source, target}]
+	}
+	graph := New()
+	for edge := range weights {
+		graph.AddEdge(edge.Source, edge.Target)
+	}
+	tree, total, err := graph.MinimumArborescence("r", weight)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if total != 18 {
+		t.Errorf("Wrong total weight (was %v, expected %v)", total, 18)
+	}
+	checkArborescence(t, graph, tree, "r", weight, total)
+
+	graph.AddVertex("x")
+	if _, _, err := graph.MinimumArborescence("r", weight); err == nil {
+		t.Errorf("No error for unreachable vertex")
+	}
+	if _, _, err := graph.MinimumArborescence("y", weight); err == nil {
+		t.Errorf("No error for missing root")
+	}
+}
+
+// bruteArborescence computes the weight of the minimum arborescence by
+// trying all possible choices of parent for each vertex.
+func bruteArborescence(order int, weights map[Edge]float64) float64 {
+	best := math.Inf(1)
+	parent := make([]int, order)
+	var try func(vertex int)
+	try = func(vertex int) {
+		if vertex == order {
+			sum := 0.0
+			for v := 1; v < order; v++ {
+				// Check that the root is reached from
+				// each vertex.
+				u, steps := v, 0
+				for u != 0 && steps < order {
+					u, steps = parent[u], steps+1
+				}
+				if u != 0 {
+					return
+				}
+				sum += weights[Edge{parent[v], v}]
+			}
+			best = math.Min(best, sum)
+			return
+		}
+		for p := 0; p < order; p++ {
+			if _, ok := weights[Edge{p, vertex}]; ok && p != vertex {
+				parent[vertex] = p
+				try(vertex + 1)
+			}
+		}
+	}
+	try(1)
+	return best
+}
+
+func TestRandomArborescence(t *testing.T) {
+	random := rand.New(rand.NewSource(4711))
+	for round := 0; round < 200; round++ {
+		order := 2 + random.Intn(5)
+		weights := make(map[Edge]float64)
+		graph := New()
+		for v := 0; v < order; v++ {
+			graph.AddVertex(v)
+		}
+		for i := 0; i < order*order; i++ {
+			edge := Edge{random.Intn(order), random.Intn(order)}
+			weights[edge] = float64(random.Intn(10))
+			graph.AddEdge(edge.Source, edge.Target)
+		}
+		weight := func(source, target Vertex) float64 {
+			return weights[Edge{source, target}]
+		}
+		expected := bruteArborescence(order, weights)
+		tree, total, err := graph.MinimumArborescence(0, weight)
+		if math.IsInf(expected, 1) {
+			if err == nil {
+				t.Errorf("No error for graph without arborescence")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if total != expected {
+			t.Errorf("Wrong total weight (was %v, expected %v)", total, expected)
+		}
+		checkArborescence(t, graph, tree, 0, weight, total)
+	}
+}
@@ -5,65 +5,187 @@
 
 package directed
 
-import "container/list"
-
+import (
+	"context"
+	"errors"
+)
 
 type vertexPair struct {
 	parent Vertex
 	child  Vertex
+	skip   bool
 }
 
-func (graph *Graph) breadthFirstVisit(walker Walker, seen map[Vertex]uint8, vertex Vertex) error {
-	queue := list.New()
+// LevelWalker interface can be implemented by a walker to be told
+// about each level of a breadth-first walk. The level of a vertex is
+// the number of edges on the shortest path to it from the root of the
+// walk. OnLevel is called with all the vertices of a level after they
+// have been discovered, but before any of them is finished.
+type LevelWalker interface {
+	Walker
+	OnLevel(level int, vertices []Vertex) error
+}
 
-	vp := new(vertexPair)
-	vp.parent = nil
-	vp.child = vertex
+// vertexWalker is implemented by the walkers of the package that only
+// look at the vertices, so that the non-tree edges of a breadth-first
+// walk do not need to be classified.
+type vertexWalker interface {
+	vertexOnly()
+}
 
-	seen[vp.child] = GREY
-	if err := walker.OnDiscover(vp.parent, vp.child); err != nil {
-		return err
+// breadthFirstVisit will perform a breadth-first walk starting with a
+// single vertex. The walker can return SkipChildren from OnDiscover to
+// avoid following the out-edges of the vertex, or from OnTreeEdge to
+// avoid discovering the target through the edge, and StopWalk from any
+// callback to end the walk.
+func (graph *Graph) breadthFirstVisit(walker Walker, seen map[Vertex]uint8, cancel *canceller, vertex Vertex) error {
+	return graph.breadthFirstSearch(walker, seen, cancel, []Vertex{vertex}, -1)
+}
+
+// breadthFirstSearch will perform a breadth-first walk starting with
+// all the source vertices on level zero. The walk processes one level
+// at a time, which gives the same order as a walk using a queue, but
+// keeps track of the level of each vertex. Out-edges of vertices on
+// level 'maxDepth' are not followed, unless 'maxDepth' is negative.
+//
+// Edges that are not tree edges are reported as back edges if they
+// lead to the source vertex itself or to one of its ancestors in the
+// breadth-first tree, and as cross edges otherwise. Since an ancestor
+// is always on an earlier level, only edges to earlier levels of the
+// same walk need to be checked, which is done by following the parents
+// from the source vertex up to the level of the target vertex. Since
+// this costs time proportional to the depth of the tree for each such
+// edge, it is not done for the walkers of the package that only look
+// at the vertices: all non-tree edges except self-loops are reported
+// as cross edges to them.
+func (graph *Graph) breadthFirstSearch(walker Walker, seen map[Vertex]uint8, cancel *canceller, sources []Vertex, maxDepth int) error {
+	levelWalker, reportLevels := walker.(LevelWalker)
+	edgeWalker, treeEdges := walker.(EdgeWalker)
+	_, vertexOnly := walker.(vertexWalker)
+	var levels map[Vertex]int
+	var parents map[Vertex]Vertex
+	if !vertexOnly {
+		levels = make(map[Vertex]int)
+		parents = make(map[Vertex]Vertex)
 	}
+	var frontier, next []*vertexPair
 
-	queue.PushFront(vp)
+	// Discover a vertex and add it to the next level.
+	discover := func(parent, child Vertex, level int) error {
+		seen[child] = GREY
+		if levels != nil {
+			levels[child] = level
+			parents[child] = parent
+		}
+		err := walker.OnDiscover(parent, child)
+		if err != nil && !errors.Is(err, SkipChildren) {
+			return err
+		}
+		next = append(next, &vertexPair{parent, child, errors.Is(err, SkipChildren)})
+		return nil
+	}
 
-	for queue.Len() != 0 {
-		ve := queue.Front()
-		queue.Remove(ve)
-		v := ve.Value.(*vertexPair)
+	for _, source := range sources {
+		if seen[source] == WHITE {
+			if err := discover(nil, source, 0); err != nil {
+				return err
+			}
+		}
+	}
 
+	for level := 0; len(next) > 0; level++ {
+		frontier, next = next, nil
+		if reportLevels {
+			vertices := make([]Vertex, len(frontier))
+			for i, v := range frontier {
+				vertices[i] = v.child
+			}
+			err := levelWalker.OnLevel(level, vertices)
+			if err != nil && !errors.Is(err, SkipChildren) {
+				return err
+			}
+		}
 
-		graph.DoOutEdges(v.child, func(from Vertex, to Vertex) error {
-			if seen[to] == WHITE {
-				seen[to] = GREY
-				if err := walker.OnDiscover(from, to); err != nil {
+		for _, v := range frontier {
+			if err := cancel.check(); err != nil {
+				return err
+			}
+			if !v.skip && (maxDepth < 0 || level < maxDepth) {
+				err := graph.DoOutEdges(v.child, func(from Vertex, to Vertex) error {
+					if err := cancel.check(); err != nil {
+						return err
+					}
+					if seen[to] == WHITE {
+						if treeEdges {
+							err := edgeWalker.OnTreeEdge(from, to)
+							if errors.Is(err, SkipChildren) {
+								return nil
+							}
+							if err != nil {
+								return err
+							}
+						}
+						return discover(from, to, level+1)
+					}
+					ancestor := from
+					if toLevel, ok := levels[to]; ok {
+						for levels[ancestor] > toLevel {
+							ancestor = parents[ancestor]
+						}
+					}
+					var err error
+					if ancestor == to {
+						err = walker.OnBackEdge(from, to)
+					} else {
+						err = walker.OnCrossEdge(from, to)
+					}
+					if errors.Is(err, SkipChildren) {
+						return nil
+					}
+					return err
+				})
+				if err != nil {
 					return err
 				}
-
-				nvp := new(vertexPair)
-				nvp.parent = from
-				nvp.child = to
-				queue.PushBack(nvp)
 			}
-			return nil
-		})
 
-		seen[v.child] = BLACK
-		if err := walker.OnFinish(v.parent, v.child); err != nil {
-			return err
+			seen[v.child] = BLACK
+			if err := walker.OnFinish(v.parent, v.child); err != nil && !errors.Is(err, SkipChildren) {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-// BreadthFirstWalkFromVertex uses the passed walker to traverse 
-// the graph breadth-first. The search starts at the given vertex. 
+// BreadthFirstWalkFromVertex uses the passed walker to traverse
+// the graph breadth-first. The search starts at the given vertex.
 // Vertices with no path to the given vertex will NOT be discovered.
-func (graph *Graph) BreadthFirstWalkFromVertex(walker Walker, vertex Vertex) {
+func (graph *Graph) BreadthFirstWalkFromVertex(walker Walker, vertex Vertex) error {
 	seen := make(map[Vertex]uint8)
-	graph.breadthFirstVisit(walker, seen, vertex)
+	cancel := newCanceller(context.Background())
+	return walkError(graph.breadthFirstVisit(walker, seen, cancel, vertex))
+}
 
+// BreadthFirstWalkFrom uses the passed walker to traverse the graph
+// breadth-first starting from the given roots, in order. A new
+// breadth-first tree is started from each root that was not
+// discovered from an earlier root. Vertices that are not reachable
+// from any of the roots are not discovered, and roots that are not in
+// the graph are ignored.
+func (graph *Graph) BreadthFirstWalkFrom(walker Walker, roots ...Vertex) error {
+	seen := make(map[Vertex]uint8)
+	cancel := newCanceller(context.Background())
+	for _, root := range roots {
+		if seen[root] != WHITE || !graph.HasVertex(root) {
+			continue
+		}
+		if err := graph.breadthFirstVisit(walker, seen, cancel, root); err != nil {
+			return walkError(err)
+		}
+	}
+	return nil
 }
 
 type fillableWalker struct {
@@ -71,10 +193,16 @@ type fillableWalker struct {
 }
 
 func (w *fillableWalker) OnDiscover(parent, vertex Vertex) error {
+	if w.onDiscover == nil {
+		return nil
+	}
 	return w.onDiscover(vertex)
 }
 
 func (w *fillableWalker) OnFinish(parent, vertex Vertex) error {
+	if w.onFinish == nil {
+		return nil
+	}
 	return w.onFinish(vertex)
 }
 
@@ -86,43 +214,148 @@ func (w *fillableWalker) OnCrossEdge(parent, vertex Vertex) error {
 	return nil
 }
 
+func (w *fillableWalker) vertexOnly() {}
 
-// DoBreadthFirstWalkFromVertex performs a breadth-first search starting at 
+// DoBreadthFirstWalkFromVertex performs a breadth-first search starting at
 // the given vertex, calling the onDiscover function when a new vertex is
 // discovered and the onFinish function  when a vertex has been traversed.
-func (graph *Graph) DoBreadthFirstWalkFromVertex(startAt Vertex,onDiscover, onFinish VertexWalkFunc) {
-	walker := &fillableWalker {
+func (graph *Graph) DoBreadthFirstWalkFromVertex(startAt Vertex, onDiscover, onFinish VertexWalkFunc) error {
+	walker := &fillableWalker{
 		onDiscover: onDiscover,
-		onFinish: onFinish,
+		onFinish:   onFinish,
 	}
 
-	graph.BreadthFirstWalkFromVertex(walker, startAt)
-		
+	return graph.BreadthFirstWalkFromVertex(walker, startAt)
 }
 
-// DoBreadthFirstWalk performs a breadth-first search walk over the entire 
-// graph, starting at an  arbitrary vertex and completing after all nodes in 
+// DoBreadthFirstWalk performs a breadth-first search walk over the entire
+// graph, starting at an  arbitrary vertex and completing after all nodes in
 // the graph are traversed.
-func (graph *Graph) DoBreadthFirstWalk(onDiscover, onFinish VertexWalkFunc) {
-	walker := &fillableWalker {
+func (graph *Graph) DoBreadthFirstWalk(onDiscover, onFinish VertexWalkFunc) error {
+	walker := &fillableWalker{
 		onDiscover: onDiscover,
-		onFinish: onFinish,
+		onFinish:   onFinish,
 	}
 
-	graph.BreadthFirstWalk(walker)
-		
+	return graph.BreadthFirstWalk(walker)
 }
 
-// BreadthFirstWalk uses the provided walker to perform a breadth-first search 
-// over the entire graph, starting at an arbitrary vertex, and completing 
-// after all nodes in the graph are traversed.
-func (graph *Graph) BreadthFirstWalk(walker Walker) {
+// BreadthFirstWalk uses the provided walker to perform a breadth-first search
+// over the entire graph, completing after all nodes in the graph are
+// traversed. New breadth-first trees are started from the vertices in
+// the order they were added to the graph, so the walk is
+// deterministic.
+func (graph *Graph) BreadthFirstWalk(walker Walker) error {
+	return graph.BreadthFirstWalkContext(context.Background(), walker)
+}
+
+// BreadthFirstWalkContext performs a breadth-first walk over the
+// entire graph in the same way as BreadthFirstWalk, but the walk is
+// aborted with the error of the context if the context is cancelled.
+func (graph *Graph) BreadthFirstWalkContext(ctx context.Context, walker Walker) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	seen := make(map[Vertex]uint8)
-	graph.DoVertices(func (vertex Vertex) error {
-		if err := graph.breadthFirstVisit(walker, seen, vertex); err != nil {
-			return err
+	cancel := newCanceller(ctx)
+	return walkError(graph.DoVertices(func(vertex Vertex) error {
+		if seen[vertex] != WHITE {
+			return nil
 		}
+		return graph.breadthFirstVisit(walker, seen, cancel, vertex)
+	}))
+}
 
-		return nil
-	})
+// BreadthFirstSearch uses the provided walker to perform a
+// breadth-first search starting from all the given sources at the
+// same time, that is, all the sources are on level zero. If
+// 'maxDepth' is not negative, the search stops at that level: vertices
+// on level 'maxDepth' are discovered and finished, but their out-edges
+// are not followed. Sources that are not in the graph are ignored.
+//
+// If the walker implement LevelWalker, it will be called with the
+// vertices of each level.
+func (graph *Graph) BreadthFirstSearch(walker Walker, maxDepth int, sources ...Vertex) error {
+	present := make([]Vertex, 0, len(sources))
+	for _, source := range sources {
+		if graph.HasVertex(source) {
+			present = append(present, source)
+		}
+	}
+	seen := make(map[Vertex]uint8)
+	cancel := newCanceller(context.Background())
+	return walkError(graph.breadthFirstSearch(walker, seen, cancel, present, maxDepth))
+}
+
+// DistanceWalkFunc is a function called with a vertex and the distance
+// to the vertex, that is, the number of edges on the shortest path to
+// it.
+type DistanceWalkFunc func(vertex Vertex, distance int) error
+
+// LevelWalkFunc is a function called with all the vertices of one
+// level of a breadth-first walk.
+type LevelWalkFunc func(level int, vertices []Vertex) error
+
+// distanceWalker keeps track of the distance to each vertex of a
+// breadth-first walk.
+type distanceWalker struct {
+	DefaultWalker
+	distance map[Vertex]int
+	onVertex DistanceWalkFunc
+	onLevel  LevelWalkFunc
+}
+
+func (walker *distanceWalker) OnDiscover(parent, vertex Vertex) error {
+	distance := 0
+	if parent != nil {
+		distance = walker.distance[parent] + 1
+	}
+	walker.distance[vertex] = distance
+	if walker.onVertex != nil {
+		return walker.onVertex(vertex, distance)
+	}
+	return nil
+}
+
+func (walker *distanceWalker) vertexOnly() {}
+
+func (walker *distanceWalker) OnLevel(level int, vertices []Vertex) error {
+	if walker.onLevel != nil {
+		return walker.onLevel(level, vertices)
+	}
+	return nil
+}
+
+// DoDistances performs a breadth-first search from the sources and
+// calls 'onVertex' with each vertex discovered and its distance from
+// the closest source. The search is limited to 'maxDepth' levels
+// unless it is negative.
+func (graph *Graph) DoDistances(maxDepth int, onVertex DistanceWalkFunc, sources ...Vertex) error {
+	walker := &distanceWalker{
+		distance: make(map[Vertex]int),
+		onVertex: onVertex,
+	}
+	return graph.BreadthFirstSearch(walker, maxDepth, sources...)
+}
+
+// DoLevels performs a breadth-first search from the sources and calls
+// 'onLevel' once for each level with all the vertices at that
+// distance from the closest source. The search is limited to
+// 'maxDepth' levels unless it is negative.
+func (graph *Graph) DoLevels(maxDepth int, onLevel LevelWalkFunc, sources ...Vertex) error {
+	walker := &distanceWalker{
+		distance: make(map[Vertex]int),
+		onLevel:  onLevel,
+	}
+	return graph.BreadthFirstSearch(walker, maxDepth, sources...)
+}
+
+// Distances will return the distance to each vertex reachable from
+// the sources within 'maxDepth' edges, or all reachable vertices if
+// 'maxDepth' is negative. The distance is the number of edges on the
+// shortest path from any of the sources.
+func (graph *Graph) Distances(maxDepth int, sources ...Vertex) map[Vertex]int {
+	walker := &distanceWalker{distance: make(map[Vertex]int)}
+	graph.BreadthFirstSearch(walker, maxDepth, sources...)
+	return walker.distance
 }
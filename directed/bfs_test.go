@@ -5,7 +5,10 @@
 
 package directed
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestNonCyclicBreadthFirstWalk(t *testing.T) {
 
@@ -261,3 +264,157 @@ func TestBreadthFirstWalk(t *testing.T) {
 	
 
 }
+
+func TestBreadthFirstWalkFrom(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("a", "c")
+	graph.AddEdge("c", "d")
+	graph.AddEdge("e", "d")
+	graph.AddEdge("f", "a")
+
+	var order []Vertex
+	graph.DoBreadthFirstWalk(func(vertex Vertex) error {
+		order = append(order, vertex)
+		return nil
+	}, nil)
+	if fmt.Sprint(order) != "[a b c d e f]" {
+		t.Errorf("Wrong discovery order: %v", order)
+	}
+
+	order = nil
+	walker := &fillableWalker{
+		onDiscover: func(vertex Vertex) error {
+			order = append(order, vertex)
+			return nil
+		},
+	}
+	graph.BreadthFirstWalkFrom(walker, "c", "e", "x")
+	if fmt.Sprint(order) != "[c d e]" {
+		t.Errorf("Wrong discovery order: %v", order)
+	}
+
+	// The walk should stop when StopWalk is returned.
+	order = nil
+	err := graph.DoBreadthFirstWalk(func(vertex Vertex) error {
+		order = append(order, vertex)
+		if vertex == "b" {
+			return StopWalk
+		}
+		return nil
+	}, nil)
+	if err != nil || fmt.Sprint(order) != "[a b]" {
+		t.Errorf("Walk not stopped (error %v, order %v)", err, order)
+	}
+}
+
+func TestDistances(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+	graph.AddEdge("c", "d")
+	graph.AddEdge("d", "e")
+	graph.AddEdge("x", "c")
+	graph.AddEdge("e", "a")
+
+	distance := graph.Distances(-1, "a")
+	if fmt.Sprint(distance) != "map[a:0 b:1 c:2 d:3 e:4]" {
+		t.Errorf("Wrong distances: %v", distance)
+	}
+
+	distance = graph.Distances(2, "a", "x", "y")
+	if fmt.Sprint(distance) != "map[a:0 b:1 c:1 d:2 x:0]" {
+		t.Errorf("Wrong distances: %v", distance)
+	}
+
+	var levels []string
+	graph.DoLevels(-1, func(level int, vertices []Vertex) error {
+		levels = append(levels, fmt.Sprint(level, vertices))
+		return nil
+	}, "x", "b")
+	if fmt.Sprint(levels) != "[0 [x b] 1 [c] 2 [d] 3 [e] 4 [a]]" {
+		t.Errorf("Wrong levels: %v", levels)
+	}
+
+	count := 0
+	graph.DoDistances(1, func(vertex Vertex, distance int) error {
+		count++
+		if distance > 1 {
+			t.Errorf("Vertex %v at distance %d discovered", vertex, distance)
+		}
+		return nil
+	}, "d")
+	if count != 2 {
+		t.Errorf("Wrong number of vertices discovered: %d", count)
+	}
+}
+
+func TestBreadthFirstEdgeClassification(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("a", "c")
+	graph.AddEdge("b", "c")
+	graph.AddEdge("b", "d")
+	graph.AddEdge("c", "d")
+	graph.AddEdge("d", "a")
+	graph.AddEdge("d", "d")
+	graph.AddEdge("d", "c")
+
+	walker := &classifyWalker{}
+	graph.BreadthFirstWalkFromVertex(walker, "a")
+	expected := []string{
+		"discover <nil> a",
+		"tree a b", "discover a b", "tree a c", "discover a c",
+		"finish <nil> a",
+		"cross b c", "tree b d", "discover b d",
+		"finish a b",
+		"cross c d",
+		"finish a c",
+		"back d a", "back d d", "cross d c",
+		"finish b d",
+	}
+	if fmt.Sprint(walker.events) != fmt.Sprint(expected) {
+		t.Errorf("Wrong events:\n%v\nexpected:\n%v", walker.events, expected)
+	}
+}
+
+// backEdgeWalker counts the back edges of a walk.
+type backEdgeWalker struct {
+	DefaultWalker
+	count int
+}
+
+func (walker *backEdgeWalker) OnBackEdge(source, target Vertex) error {
+	walker.count++
+	return nil
+}
+
+// BenchmarkBreadthFirstBackEdges will walk a long path where each vertex
+// has an edge back to the first vertex, which is the worst case for
+// classifying the non-tree edges.
+func BenchmarkBreadthFirstBackEdges(b *testing.B) {
+	const n = 2000
+	graph := New()
+	for i := 1; i < n; i++ {
+		graph.AddEdge(i-1, i)
+		graph.AddEdge(i, 0)
+	}
+	graph.AddVertex("isolated")
+
+	b.Run("classify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			walker := &backEdgeWalker{}
+			graph.BreadthFirstWalkFrom(walker, 0)
+			if walker.count != n-1 {
+				b.Fatalf("Expected %d back edges, got %d", n-1, walker.count)
+			}
+		}
+	})
+	b.Run("reachable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if graph.Reachable(0, "isolated") {
+				b.Fatalf("Unexpected path")
+			}
+		}
+	})
+}
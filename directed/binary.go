@@ -0,0 +1,410 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// The binary snapshot format consists of a header, a vertex table, the
+// out-edges of the vertices in compressed sparse row format, optional
+// edge weights, and a checksum. All numbers are little-endian.
+//
+//	magic       4 bytes "GGSN"
+//	version     uint32
+//	flags       uint32
+//	order       uint64
+//	size        uint64
+//	vertices    order × (uvarint length, bytes of ID)
+//	degrees     order × uint32
+//	targets     size × uint32
+//	weights     size × float64, if flagWeighted is set
+//	checksum    uint32, CRC-32C of everything before it
+const (
+	snapshotMagic   = "GGSN"
+	snapshotVersion = 1
+	flagWeighted    = 1 << 0
+
+	// maxIDLength is the longest vertex ID accepted when reading,
+	// to avoid huge allocations for corrupt snapshots.
+	maxIDLength = 1 << 24
+
+	// chunkSize is the number of values encoded or decoded at a
+	// time.
+	chunkSize = 8192
+)
+
+// ErrCorruptSnapshot is returned, possibly wrapped, when reading a
+// snapshot that is malformed or where the checksum does not match.
+var ErrCorruptSnapshot = errors.New("corrupt snapshot")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// snapshotWriter computes the checksum and counts the bytes written.
+type snapshotWriter struct {
+	out     *bufio.Writer
+	crc     hash.Hash32
+	written int64
+	buf     []byte
+}
+
+func (w *snapshotWriter) Write(data []byte) (int, error) {
+	w.crc.Write(data)
+	n, err := w.out.Write(data)
+	w.written += int64(n)
+	return n, err
+}
+
+func (w *snapshotWriter) uint32(value uint32) {
+	w.buf = binary.LittleEndian.AppendUint32(w.buf[:0], value)
+	w.Write(w.buf)
+}
+
+func (w *snapshotWriter) uint64(value uint64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf[:0], value)
+	w.Write(w.buf)
+}
+
+// WriteTo will write a binary snapshot of the graph to 'out'. The
+// vertices are written using StringCodec; use WithCodec to write
+// graphs where the vertices are not strings. It implements
+// io.WriterTo.
+func (graph *Graph) WriteTo(out io.Writer) (int64, error) {
+	return graph.WithCodec(nil).WriteWeightedTo(out, nil)
+}
+
+// WriteWeightedTo will write a binary snapshot of the graph to 'out'
+// in the same way as WriteTo, but including the weight of each edge
+// given by 'weight', unless it is nil.
+func (graph *Graph) WriteWeightedTo(out io.Writer, weight WeightFunc) (int64, error) {
+	return graph.WithCodec(nil).WriteWeightedTo(out, weight)
+}
+
+// WriteTo will write a binary snapshot of the graph to 'out', using
+// the codec to write the vertices, so the same codec has to be used
+// when reading the snapshot.
+func (graph *CodecGraph) WriteTo(out io.Writer) (int64, error) {
+	return graph.WriteWeightedTo(out, nil)
+}
+
+// WriteWeightedTo will write a binary snapshot of the graph to 'out'
+// in the same way as WriteTo, but including the weight of each edge
+// given by 'weight', unless it is nil.
+func (graph *CodecGraph) WriteWeightedTo(out io.Writer, weight WeightFunc) (int64, error) {
+	codec := orDefault(graph.Codec)
+	compact := graph.Compact()
+	w := &snapshotWriter{
+		out: bufio.NewWriterSize(out, 1<<16),
+		crc: crc32.New(crcTable),
+	}
+
+	var flags uint32
+	if weight != nil {
+		flags |= flagWeighted
+	}
+	w.Write([]byte(snapshotMagic))
+	w.uint32(snapshotVersion)
+	w.uint32(flags)
+	w.uint64(uint64(compact.Order()))
+	w.uint64(uint64(compact.Size()))
+
+	for _, vertex := range compact.vertices {
+		id, err := codec.EncodeVertex(vertex)
+		if err != nil {
+			return w.written, err
+		}
+		w.buf = binary.AppendUvarint(w.buf[:0], uint64(len(id)))
+		w.buf = append(w.buf, id...)
+		w.Write(w.buf)
+	}
+
+	chunk := make([]byte, 0, 8*chunkSize)
+	flush := func(force bool) {
+		if force || len(chunk) == cap(chunk) {
+			w.Write(chunk)
+			chunk = chunk[:0]
+		}
+	}
+	for i := 0; i < compact.Order(); i++ {
+		chunk = binary.LittleEndian.AppendUint32(chunk, uint32(len(compact.OutEdges(i))))
+		flush(false)
+	}
+	flush(true)
+	for _, target := range compact.outTargets {
+		chunk = binary.LittleEndian.AppendUint32(chunk, uint32(target))
+		flush(false)
+	}
+	flush(true)
+	if weight != nil {
+		for i, vertex := range compact.vertices {
+			for _, target := range compact.OutEdges(i) {
+				value := weight(vertex, compact.vertices[target])
+				chunk = binary.LittleEndian.AppendUint64(chunk, math.Float64bits(value))
+				flush(false)
+			}
+		}
+		flush(true)
+	}
+
+	sum := w.crc.Sum32()
+	w.buf = binary.LittleEndian.AppendUint32(w.buf[:0], sum)
+	n, _ := w.out.Write(w.buf)
+	w.written += int64(n)
+	return w.written, w.out.Flush()
+}
+
+// byteReader is the reader needed to decode a snapshot.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// snapshotReader computes the checksum and counts the bytes read.
+type snapshotReader struct {
+	in   byteReader
+	crc  hash.Hash32
+	read int64
+	buf  []byte
+}
+
+func (r *snapshotReader) Read(data []byte) (int, error) {
+	n, err := r.in.Read(data)
+	r.crc.Write(data[:n])
+	r.read += int64(n)
+	return n, err
+}
+
+func (r *snapshotReader) ReadByte() (byte, error) {
+	b, err := r.in.ReadByte()
+	if err == nil {
+		r.crc.Write([]byte{b})
+		r.read++
+	}
+	return b, err
+}
+
+// full will read exactly 'n' bytes. The returned slice is only valid
+// until the next call.
+func (r *snapshotReader) full(n int) ([]byte, error) {
+	if cap(r.buf) < n {
+		r.buf = make([]byte, n)
+	}
+	r.buf = r.buf[:n]
+	if _, err := io.ReadFull(r, r.buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return r.buf, nil
+}
+
+// uint32s will read 'count' uint32 values, calling 'fn' with each.
+func (r *snapshotReader) uint32s(count uint64, fn func(i int, value uint32) error) error {
+	for i := uint64(0); i < count; {
+		n := count - i
+		if n > chunkSize {
+			n = chunkSize
+		}
+		data, err := r.full(int(4 * n))
+		if err != nil {
+			return err
+		}
+		for k := 0; k < int(n); k++ {
+			if err := fn(int(i)+k, binary.LittleEndian.Uint32(data[4*k:])); err != nil {
+				return err
+			}
+		}
+		i += n
+	}
+	return nil
+}
+
+// corrupt will return an error wrapping ErrCorruptSnapshot.
+func corrupt(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrCorruptSnapshot, fmt.Sprintf(format, args...))
+}
+
+// ReadFrom will replace the contents of the graph with a binary
+// snapshot read from 'in', ignoring any weights in the snapshot. The
+// vertices are decoded using StringCodec; use WithCodec to read
+// graphs where the vertices are not strings. It implements
+// io.ReaderFrom.
+//
+// If 'in' does not implement io.ByteReader, it is buffered, so more
+// bytes than the size of the snapshot may be read from it.
+func (graph *Graph) ReadFrom(in io.Reader) (int64, error) {
+	_, n, err := graph.WithCodec(nil).ReadWeightedFrom(in)
+	return n, err
+}
+
+// ReadWeightedFrom will replace the contents of the graph with a
+// binary snapshot read from 'in' in the same way as ReadFrom, and
+// return the weights of the edges, or nil if the snapshot has no
+// weights.
+func (graph *Graph) ReadWeightedFrom(in io.Reader) (Weights, int64, error) {
+	return graph.WithCodec(nil).ReadWeightedFrom(in)
+}
+
+// ReadFrom will replace the contents of the graph with a binary
+// snapshot read from 'in' in the same way as Graph.ReadFrom, but
+// using the codec to decode the vertices.
+func (graph *CodecGraph) ReadFrom(in io.Reader) (int64, error) {
+	_, n, err := graph.ReadWeightedFrom(in)
+	return n, err
+}
+
+// ReadWeightedFrom will replace the contents of the graph with a
+// binary snapshot read from 'in' in the same way as ReadFrom, and
+// return the weights of the edges, or nil if the snapshot has no
+// weights.
+func (graph *CodecGraph) ReadWeightedFrom(in io.Reader) (Weights, int64, error) {
+	codec := orDefault(graph.Codec)
+	br, ok := in.(byteReader)
+	if !ok {
+		br = bufio.NewReaderSize(in, 1<<16)
+	}
+	r := &snapshotReader{in: br, crc: crc32.New(crcTable)}
+
+	header, err := r.full(4 + 4 + 4 + 8 + 8)
+	if err != nil {
+		return nil, r.read, err
+	}
+	if string(header[:4]) != snapshotMagic {
+		return nil, r.read, corrupt("bad magic %q", header[:4])
+	}
+	version := binary.LittleEndian.Uint32(header[4:])
+	flags := binary.LittleEndian.Uint32(header[8:])
+	order := binary.LittleEndian.Uint64(header[12:])
+	size := binary.LittleEndian.Uint64(header[20:])
+	if version != snapshotVersion {
+		return nil, r.read, fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	if flags&^flagWeighted != 0 {
+		return nil, r.read, corrupt("unknown flags %#x", flags)
+	}
+	if order > math.MaxInt32 {
+		return nil, r.read, corrupt("bad order %d", order)
+	}
+
+	result := New()
+
+	// The slices are grown while reading instead of allocated up
+	// front, so a corrupt header does not cause huge allocations.
+	var vertices []Vertex
+	var lists []*list.List
+	for i := uint64(0); i < order; i++ {
+		length, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, r.read, err
+		}
+		if length > maxIDLength {
+			return nil, r.read, corrupt("vertex ID of length %d", length)
+		}
+		id, err := r.full(int(length))
+		if err != nil {
+			return nil, r.read, err
+		}
+		vertex, err := codec.DecodeVertex(string(id))
+		if err != nil {
+			return nil, r.read, err
+		}
+		if !result.AddVertex(vertex) {
+			return nil, r.read, corrupt("duplicate vertex %#v", vertex)
+		}
+		vertices = append(vertices, vertex)
+		lists = append(lists, result.edges[vertex])
+	}
+
+	var degrees []uint32
+	total := uint64(0)
+	err = r.uint32s(order, func(i int, degree uint32) error {
+		degrees = append(degrees, degree)
+		total += uint64(degree)
+		return nil
+	})
+	if err != nil {
+		return nil, r.read, err
+	}
+	if total != size {
+		return nil, r.read, corrupt("degrees sum to %d, expected %d", total, size)
+	}
+
+	// The edges are added directly to the adjacency lists, since
+	// the edges of a snapshot are known to be distinct except in a
+	// corrupt snapshot, which is detected using 'seen'.
+	seen := make([]int, order)
+	source, remaining := 0, uint32(0)
+	err = r.uint32s(size, func(_ int, target uint32) error {
+		for remaining == 0 {
+			source++
+			remaining = degrees[source-1]
+		}
+		remaining--
+		if uint64(target) >= order {
+			return corrupt("target %d out of range", target)
+		}
+		if seen[target] == source {
+			return corrupt("duplicate edge %d -> %d", source-1, target)
+		}
+		seen[target] = source
+		lists[source-1].PushBack(vertices[target])
+		return nil
+	})
+	if err != nil {
+		return nil, r.read, err
+	}
+	result.edgeCount = int(size)
+
+	var weights Weights
+	if flags&flagWeighted != 0 {
+		weights = make(Weights)
+		i, elem := 0, (*list.Element)(nil)
+		for done := uint64(0); done < size; {
+			n := size - done
+			if n > chunkSize {
+				n = chunkSize
+			}
+			data, err := r.full(int(8 * n))
+			if err != nil {
+				return nil, r.read, err
+			}
+			for k := 0; k < int(n); k++ {
+				for elem == nil {
+					elem = lists[i].Front()
+					i++
+				}
+				value := math.Float64frombits(binary.LittleEndian.Uint64(data[8*k:]))
+				weights[Edge{vertices[i-1], elem.Value}] = value
+				elem = elem.Next()
+			}
+			done += n
+		}
+	}
+
+	expected := r.crc.Sum32()
+	sum, err := r.full(4)
+	if err != nil {
+		return nil, r.read, err
+	}
+	if binary.LittleEndian.Uint32(sum) != expected {
+		return nil, r.read, corrupt("checksum mismatch")
+	}
+
+	*graph.Graph = *result
+	return weights, r.read, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("a", "c")
+	graph.AddEdge("c", "a")
+	graph.AddEdge("c", "c")
+	graph.AddVertex("")
+	graph.AddVertex("åäö")
+
+	var buf bytes.Buffer
+	written, err := graph.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if written != int64(buf.Len()) {
+		t.Errorf("WriteTo reported %d bytes, wrote %d", written, buf.Len())
+	}
+
+	result := New()
+	result.AddEdge("old", "edge")
+	read, err := result.ReadFrom(&buf)
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if read != written {
+		t.Errorf("ReadFrom reported %d bytes, expected %d", read, written)
+	}
+	checkGraphCount(t, result, 5, 4)
+	if got, expected := edgeString(result), edgeString(graph); got != expected {
+		t.Errorf("Expected edges %s, got %s", expected, got)
+	}
+	if !result.HasVertex("") || !result.HasVertex("åäö") {
+		t.Errorf("Isolated vertices missing")
+	}
+}
+
+func TestWeightedSnapshot(t *testing.T) {
+	random := rand.New(rand.NewSource(99))
+	graph := New()
+	weights := make(Weights)
+	for i := 0; i < 5000; i++ {
+		source, target := random.Intn(1000), random.Intn(1000)
+		graph.AddEdge(source, target)
+		weights[Edge{source, target}] = random.NormFloat64()
+	}
+
+	var buf bytes.Buffer
+	if _, err := graph.WithCodec(IntCodec{}).WriteWeightedTo(&buf, weights.Weight); err != nil {
+		t.Fatalf("WriteWeightedTo failed: %v", err)
+	}
+
+	var result Graph
+	resultWeights, _, err := result.WithCodec(IntCodec{}).ReadWeightedFrom(&buf)
+	if err != nil {
+		t.Fatalf("ReadWeightedFrom failed: %v", err)
+	}
+	checkGraphCount(t, &result, graph.Order(), graph.Size())
+	if got, expected := edgeString(&result), edgeString(graph); got != expected {
+		t.Errorf("Edges differ")
+	}
+	if len(resultWeights) != len(weights) {
+		t.Errorf("Expected %d weights, got %d", len(weights), len(resultWeights))
+	}
+	for edge, weight := range weights {
+		if resultWeights[edge] != weight {
+			t.Errorf("Expected weight %v for %v, got %v", weight, edge, resultWeights[edge])
+		}
+	}
+
+	// The snapshot can also be read without the weights.
+	buf.Reset()
+	graph.WithCodec(IntCodec{}).WriteTo(&buf)
+	resultWeights, _, err = result.WithCodec(IntCodec{}).ReadWeightedFrom(&buf)
+	if err != nil || resultWeights != nil {
+		t.Errorf("Expected no weights and no error, got %v", err)
+	}
+}
+
+func TestCorruptSnapshot(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+	graph.AddEdge("c", "a")
+	var buf bytes.Buffer
+	graph.WriteWeightedTo(&buf, func(source, target Vertex) float64 { return 1.5 })
+	data := buf.Bytes()
+
+	// Changing any single byte should be detected.
+	for i := range data {
+		corrupted := append([]byte(nil), data...)
+		corrupted[i] ^= 0x20
+		result := New()
+		if _, err := result.ReadFrom(bytes.NewReader(corrupted)); err == nil {
+			t.Errorf("Corruption of byte %d not detected", i)
+		}
+		if result.Order() != 0 {
+			t.Errorf("Graph changed by failed read")
+		}
+	}
+
+	// So should truncation.
+	for i := 0; i < len(data); i++ {
+		_, err := New().ReadFrom(bytes.NewReader(data[:i]))
+		if err != io.ErrUnexpectedEOF && !errors.Is(err, ErrCorruptSnapshot) {
+			t.Errorf("Expected error for truncation at %d, got %v", i, err)
+		}
+	}
+
+	data[len(data)-1] ^= 1
+	if _, err := New().ReadFrom(bytes.NewReader(data)); !errors.Is(err, ErrCorruptSnapshot) {
+		t.Errorf("Expected checksum error, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+// Compact is an immutable snapshot of a graph where the vertices are
+// numbered from zero and the edges are stored in compressed sparse
+// row (CSR) format, both for the out-edges and the in-edges of each
+// vertex. It uses much less memory than a Graph and can be traversed
+// quickly, which makes it suitable for algorithms on large graphs.
+//
+// The vertices are numbered in the order they were added to the
+// graph, and the edges of each vertex are in the same order as in the
+// graph.
+type Compact struct {
+	vertices   []Vertex
+	index      map[Vertex]int
+	outOffsets []int
+	outTargets []int32
+	inOffsets  []int
+	inSources  []int32
+}
+
+// Compact will create a compact snapshot of the graph. Later changes
+// to the graph do not affect the snapshot.
+func (graph *Graph) Compact() *Compact {
+	vertices, index := graph.indexVertices()
+	compact := &Compact{
+		vertices:   vertices,
+		index:      index,
+		outOffsets: make([]int, len(vertices)+1),
+		outTargets: make([]int32, 0, graph.Size()),
+		inOffsets:  make([]int, len(vertices)+1),
+		inSources:  make([]int32, graph.Size()),
+	}
+
+	// The out-edges are added in order, while the in-edges are
+	// counted first and then placed using the offsets.
+	for i, vertex := range vertices {
+		graph.DoOutEdges(vertex, func(source, target Vertex) error {
+			compact.outTargets = append(compact.outTargets, int32(index[target]))
+			compact.inOffsets[index[target]+1]++
+			return nil
+		})
+		compact.outOffsets[i+1] = len(compact.outTargets)
+	}
+	for i := range vertices {
+		compact.inOffsets[i+1] += compact.inOffsets[i]
+	}
+	fill := append([]int(nil), compact.inOffsets[:len(vertices)]...)
+	for source := range vertices {
+		for _, target := range compact.OutEdges(source) {
+			compact.inSources[fill[target]] = int32(source)
+			fill[target]++
+		}
+	}
+	return compact
+}
+
+// Order will return the number of vertices in the snapshot.
+func (compact *Compact) Order() int {
+	return len(compact.vertices)
+}
+
+// Size will return the number of edges in the snapshot.
+func (compact *Compact) Size() int {
+	return len(compact.outTargets)
+}
+
+// Vertex will return the vertex with index 'i'.
+func (compact *Compact) Vertex(i int) Vertex {
+	return compact.vertices[i]
+}
+
+// Index will return the index of 'vertex' and true, or false if the
+// vertex is not in the snapshot.
+func (compact *Compact) Index(vertex Vertex) (int, bool) {
+	i, ok := compact.index[vertex]
+	return i, ok
+}
+
+// OutEdges will return the indexes of the targets of the out-edges of
+// the vertex with index 'i'. The returned slice must not be modified.
+func (compact *Compact) OutEdges(i int) []int32 {
+	return compact.outTargets[compact.outOffsets[i]:compact.outOffsets[i+1]]
+}
+
+// InEdges will return the indexes of the sources of the in-edges of
+// the vertex with index 'i'. The returned slice must not be modified.
+func (compact *Compact) InEdges(i int) []int32 {
+	return compact.inSources[compact.inOffsets[i]:compact.inOffsets[i+1]]
+}
+
+// Graph will create a new graph from the snapshot.
+func (compact *Compact) Graph() *Graph {
+	graph := New()
+	for _, vertex := range compact.vertices {
+		graph.AddVertex(vertex)
+	}
+	for source, vertex := range compact.vertices {
+		for _, target := range compact.OutEdges(source) {
+			graph.AddEdge(vertex, compact.vertices[target])
+		}
+	}
+	return graph
+}
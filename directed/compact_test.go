@@ -0,0 +1,49 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("a", "c")
+	graph.AddEdge("c", "b")
+	graph.AddEdge("b", "b")
+	graph.AddVertex("d")
+
+	compact := graph.Compact()
+	if compact.Order() != 4 || compact.Size() != 4 {
+		t.Errorf("Wrong order %d or size %d", compact.Order(), compact.Size())
+	}
+	if i, ok := compact.Index("c"); !ok || i != 2 || compact.Vertex(i) != "c" {
+		t.Errorf("Wrong index %d for vertex %v", i, "c")
+	}
+	if _, ok := compact.Index("x"); ok {
+		t.Errorf("Index for missing vertex")
+	}
+	if out := compact.OutEdges(0); fmt.Sprint(out) != "[1 2]" {
+		t.Errorf("Wrong out-edges: %v", out)
+	}
+	if in := compact.InEdges(1); fmt.Sprint(in) != "[0 1 2]" {
+		t.Errorf("Wrong in-edges: %v", in)
+	}
+	if in := compact.InEdges(3); len(in) != 0 {
+		t.Errorf("Wrong in-edges: %v", in)
+	}
+
+	copy := compact.Graph()
+	checkGraphCount(t, copy, 4, 4)
+	graph.DoEdges(func(source, target Vertex) error {
+		if !copy.HasEdge(source, target) {
+			t.Errorf("Edge %v -> %v missing", source, target)
+		}
+		return nil
+	})
+}
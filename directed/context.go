@@ -0,0 +1,35 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import "context"
+
+// checkInterval is the number of steps a walk takes between checking
+// if its context has been cancelled.
+const checkInterval = 1024
+
+// canceller is used by walks to periodically check if the context of
+// the walk has been cancelled.
+type canceller struct {
+	ctx   context.Context
+	steps int
+}
+
+func newCanceller(ctx context.Context) *canceller {
+	return &canceller{ctx: ctx}
+}
+
+// check will count one step of the walk and return the error of the
+// context if it has been cancelled. The context is checked on the
+// first step and then every checkInterval steps, so that short walks
+// also notice a context that was cancelled before they started.
+func (c *canceller) check() error {
+	c.steps++
+	if c.steps%checkInterval == 1 {
+		return c.ctx.Err()
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"context"
+	"testing"
+)
+
+// cancelWalker cancels the context after a number of discovered
+// vertices.
+type cancelWalker struct {
+	DefaultWalker
+	cancel func()
+	count  int
+}
+
+func (walker *cancelWalker) OnDiscover(parent, vertex Vertex) error {
+	walker.count++
+	if walker.count == 10 {
+		walker.cancel()
+	}
+	return nil
+}
+
+func TestContextWalks(t *testing.T) {
+	graph := New()
+	for i := 0; i < 10*checkInterval; i++ {
+		graph.AddEdge(i, i+1)
+		graph.AddEdge(i, i+2)
+	}
+
+	walks := map[string]func(ctx context.Context, walker Walker) error{
+		"DepthFirstWalkContext":   graph.DepthFirstWalkContext,
+		"BreadthFirstWalkContext": graph.BreadthFirstWalkContext,
+	}
+	for name, walk := range walks {
+		ctx, cancel := context.WithCancel(context.Background())
+		walker := &cancelWalker{cancel: cancel}
+		if err := walk(ctx, walker); err != context.Canceled {
+			t.Errorf("%s: wrong error returned: %v", name, err)
+		}
+		if walker.count > 10+checkInterval {
+			t.Errorf("%s: walk not aborted (%d vertices discovered)", name, walker.count)
+		}
+		cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := graph.DoTopologicalContext(ctx, func(Vertex) error { return nil }); err != context.Canceled {
+		t.Errorf("DoTopologicalContext: wrong error returned: %v", err)
+	}
+	if err := graph.DoCyclesContext(ctx, func(*Graph) error { return nil }); err != context.Canceled {
+		t.Errorf("DoCyclesContext: wrong error returned: %v", err)
+	}
+	if _, err := graph.FindShortestPathContext(ctx, 0, -1); err != context.Canceled {
+		t.Errorf("FindShortestPathContext: wrong error returned: %v", err)
+	}
+
+	// Walks with a context that is not cancelled should complete.
+	path, err := graph.FindShortestPathContext(context.Background(), 0, 10)
+	if err != nil || path.Len() != 6 {
+		t.Errorf("Wrong path found (error %v)", err)
+	}
+}
+
+func TestContextCancelledSmall(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := graph.FindShortestPathContext(ctx, 1, 2); err != context.Canceled {
+		t.Errorf("FindShortestPathContext: wrong error returned: %v", err)
+	}
+	if err := graph.DepthFirstWalkContext(ctx, &DefaultWalker{}); err != context.Canceled {
+		t.Errorf("DepthFirstWalkContext: wrong error returned: %v", err)
+	}
+	if err := graph.BreadthFirstWalkContext(ctx, &DefaultWalker{}); err != context.Canceled {
+		t.Errorf("BreadthFirstWalkContext: wrong error returned: %v", err)
+	}
+	if err := graph.DoTopologicalContext(ctx, func(Vertex) error { return nil }); err != context.Canceled {
+		t.Errorf("DoTopologicalContext: wrong error returned: %v", err)
+	}
+	if err := graph.DoCyclesContext(ctx, func(*Graph) error { return nil }); err != context.Canceled {
+		t.Errorf("DoCyclesContext: wrong error returned: %v", err)
+	}
+
+	// A context cancelled when the first vertex is discovered is
+	// noticed on the first step of the walk.
+	ctx, cancel = context.WithCancel(context.Background())
+	walker := &cancelWalker{cancel: cancel}
+	walker.count = 9
+	if err := graph.DepthFirstWalkContext(ctx, walker); err != context.Canceled {
+		t.Errorf("Cancellation during walk not noticed: %v", err)
+	}
+}
@@ -5,11 +5,17 @@
 
 package directed
 
-import "fmt"
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+)
 
-// Walker interface is used by the depth-first visit function. All the
-// methods have to be implemented. To help with implementing default
-// methods (that do nothing) please embed the DefaultWalker.
+// Walker interface is used by the depth-first and breadth-first visit
+// functions. All the methods have to be implemented. To help with
+// implementing default methods (that do nothing) please embed the
+// DefaultWalker.
 type Walker interface {
 	OnDiscover(parent, vertex Vertex) error
 	OnFinish(parent, vertex Vertex) error
@@ -17,6 +23,23 @@ type Walker interface {
 	OnCrossEdge(source, target Vertex) error
 }
 
+// EdgeWalker interface can be implemented by a walker to have tree
+// edges and forward edges reported separately. Tree edges are the
+// edges used to discover new vertices and are reported before the
+// target vertex is discovered, so returning SkipChildren from
+// OnTreeEdge prevents the target from being discovered through the
+// edge. Forward edges are non-tree edges from a vertex to one of its
+// descendants.
+//
+// If the walker only implement the Walker interface, forward edges are
+// reported as cross edges. Walkers embedding DefaultWalker implement
+// this interface.
+type EdgeWalker interface {
+	Walker
+	OnTreeEdge(source, target Vertex) error
+	OnForwardEdge(source, target Vertex) error
+}
+
 // DefaultWalker implement default methods for use when implementing a
 // walker. The default methods do nothing.
 type DefaultWalker struct{}
@@ -26,17 +49,28 @@ func (walker *DefaultWalker) OnDiscover(parent, vertex Vertex) error {
 	return nil
 }
 
-// OnDiscover implement the default callback for completing nodes
+// OnFinish implement the default callback for completing nodes
 func (walker *DefaultWalker) OnFinish(parent, vertex Vertex) error {
 	return nil
 }
 
-// OnDiscover implement the default callback for discovering back edges
+// OnTreeEdge implement the default callback for discovering tree edges
+func (walker *DefaultWalker) OnTreeEdge(source, target Vertex) error {
+	return nil
+}
+
+// OnBackEdge implement the default callback for discovering back edges
 func (walker *DefaultWalker) OnBackEdge(source, target Vertex) error {
 	return nil
 }
 
-// OnDiscover implement the default callback for discovering cross edges
+// OnForwardEdge implement the default callback for discovering forward
+// edges
+func (walker *DefaultWalker) OnForwardEdge(source, target Vertex) error {
+	return nil
+}
+
+// OnCrossEdge implement the default callback for discovering cross edges
 func (walker *DefaultWalker) OnCrossEdge(source, target Vertex) error {
 	return nil
 }
@@ -47,44 +81,191 @@ const (
 	BLACK        // Finalized
 )
 
-// DepthFirstVisit will perform a depth-first walk starting with a
+// SkipChildren can be returned from the OnDiscover callback of a
+// walker to skip the out-edges of the vertex. The vertex will still be
+// finished as normal, but vertices only reachable through it will not
+// be discovered from it. Returned from the OnTreeEdge callback of an
+// EdgeWalker, the target of the edge is not discovered through the
+// edge, but it can still be discovered through another edge. Returned
+// from any other callback, it is ignored. Errors wrapping SkipChildren,
+// for example created with fmt.Errorf and %w, are treated the same
+// way.
+var SkipChildren = errors.New("skip children")
+
+// StopWalk can be returned from any callback of a walker to end the
+// walk. No more callbacks will be called and the walk will return
+// without an error. Errors wrapping StopWalk are treated the
+// same way.
+var StopWalk = errors.New("stop walk")
+
+// dfsFrame is an entry on the explicit stack used by the depth-first
+// walk. It holds a vertex that has been discovered but not finished,
+// and the next out-edge of the vertex to follow.
+type dfsFrame struct {
+	parent, vertex Vertex
+	next           *list.Element
+}
+
+// dfsState is the state of a depth-first walk, which can span several
+// depth-first trees. It keeps the color of each vertex and the time
+// each vertex was discovered, which is used to tell forward edges from
+// cross edges.
+type dfsState struct {
+	color  map[Vertex]uint8
+	number map[Vertex]int
+	time   int
+	cancel *canceller
+}
+
+func newDFSState(ctx context.Context) *dfsState {
+	return &dfsState{
+		color:  make(map[Vertex]uint8),
+		number: make(map[Vertex]int),
+		cancel: newCanceller(ctx),
+	}
+}
+
+// depthFirstVisit will perform a depth-first walk starting with a
 // single vertex and store the information in the 'walker' structure.
 // This will be a depth-first search forest, which can be used to
 // deduce other properties of the graph.
-func (graph *Graph) depthFirstVisit(walker Walker, info map[Vertex]uint8, parent, vertex Vertex) error {
-	switch info[vertex] {
-	case WHITE:
-		info[vertex] = GREY
-		if err := walker.OnDiscover(parent, vertex); err != nil {
+//
+// The walk uses an explicit stack rather than recursion, so the depth
+// of the walk is not limited by the size of the goroutine stack, but
+// the events are reported in the same order as for a recursive walk.
+func (graph *Graph) depthFirstVisit(walker Walker, state *dfsState, root Vertex) error {
+	var stack []dfsFrame
+	edgeWalker, classify := walker.(EdgeWalker)
+
+	// Discover a vertex and push it on the stack. The out-edges
+	// are not followed if the walker asks to skip the children.
+	discover := func(parent, vertex Vertex) error {
+		state.time++
+		state.color[vertex] = GREY
+		state.number[vertex] = state.time
+		frame := dfsFrame{parent: parent, vertex: vertex}
+		switch err := walker.OnDiscover(parent, vertex); {
+		case err == nil:
+			frame.next = graph.edges[vertex].Front()
+		case errors.Is(err, SkipChildren):
+		default:
 			return err
 		}
-		graph.DoOutEdges(vertex, func(source, target Vertex) error {
-			return graph.depthFirstVisit(walker, info, source, target)
-		})
-		info[vertex] = BLACK
-		if err := walker.OnFinish(parent, vertex); err != nil {
+		stack = append(stack, frame)
+		return nil
+	}
+
+	if err := discover(nil, root); err != nil {
+		return err
+	}
+	for len(stack) > 0 {
+		if err := state.cancel.check(); err != nil {
 			return err
 		}
-	case GREY:
-		// This is part of the tree we are recursing in, so
-		// this is a back edge
-		if err := walker.OnBackEdge(parent, vertex); err != nil {
-			return err
+		frame := &stack[len(stack)-1]
+		if frame.next == nil {
+			// All out-edges are processed, so the vertex is
+			// finished.
+			parent, vertex := frame.parent, frame.vertex
+			stack = stack[:len(stack)-1]
+			state.color[vertex] = BLACK
+			if err := walker.OnFinish(parent, vertex); err != nil && !errors.Is(err, SkipChildren) {
+				return err
+			}
+			continue
+		}
+
+		source, target := frame.vertex, frame.next.Value
+		frame.next = frame.next.Next()
+		var err error
+		switch state.color[target] {
+		case WHITE:
+			if classify {
+				err = edgeWalker.OnTreeEdge(source, target)
+			}
+			if err == nil {
+				err = discover(source, target)
+			}
+		case GREY:
+			// This is part of the tree we are processing,
+			// so this is a back edge
+			err = walker.OnBackEdge(source, target)
+		case BLACK:
+			// The vertex was closed, so it is either a
+			// descendant of the source, if it was
+			// discovered after the source, or in another
+			// subtree.
+			if classify && state.number[source] < state.number[target] {
+				err = edgeWalker.OnForwardEdge(source, target)
+			} else {
+				err = walker.OnCrossEdge(source, target)
+			}
 		}
-	case BLACK:
-		// The vertex was closed, so it is a cross edge.
-		if err := walker.OnCrossEdge(parent, vertex); err != nil {
+		if err != nil && !errors.Is(err, SkipChildren) {
 			return err
 		}
 	}
 	return nil
 }
 
-func (graph *Graph) DepthFirstWalk(walker Walker) {
-	seen := make(map[Vertex]uint8)
-	for vertex := range graph.edges {
-		graph.depthFirstVisit(walker, seen, nil, vertex)
+// DepthFirstWalk will perform a depth-first walk over the entire
+// graph, reporting the events to the walker. New depth-first trees
+// are started from the vertices in the order they were added to the
+// graph, so the walk is deterministic. If a walker callback returns
+// an error, the walk is aborted and the error returned, unless the
+// error is StopWalk, in which case nil is returned.
+func (graph *Graph) DepthFirstWalk(walker Walker) error {
+	return graph.DepthFirstWalkContext(context.Background(), walker)
+}
+
+// DepthFirstWalkContext will perform a depth-first walk over the
+// entire graph in the same way as DepthFirstWalk, but the walk is
+// aborted with the error of the context if the context is cancelled.
+func (graph *Graph) DepthFirstWalkContext(ctx context.Context, walker Walker) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+	state := newDFSState(ctx)
+	return walkError(graph.DoVertices(func(vertex Vertex) error {
+		return graph.depthFirstTree(walker, state, vertex)
+	}))
+}
+
+// DepthFirstWalkFrom will perform a depth-first walk starting from the
+// given roots, in order, reporting the events to the walker. A new
+// depth-first tree is started from each root that was not discovered
+// from an earlier root. Vertices that are not reachable from any of
+// the roots are not discovered, and roots that are not in the graph
+// are ignored. Errors are handled as for DepthFirstWalk.
+//
+// To walk the entire graph with the roots in a specific order, use
+// the vertices returned from SortedVertices as roots.
+func (graph *Graph) DepthFirstWalkFrom(walker Walker, roots ...Vertex) error {
+	state := newDFSState(context.Background())
+	for _, root := range roots {
+		if err := graph.depthFirstTree(walker, state, root); err != nil {
+			return walkError(err)
+		}
+	}
+	return nil
+}
+
+// depthFirstTree will walk the depth-first tree rooted at 'root'
+// unless the root is already discovered or not in the graph.
+func (graph *Graph) depthFirstTree(walker Walker, state *dfsState, root Vertex) error {
+	if state.color[root] != WHITE || !graph.HasVertex(root) {
+		return nil
+	}
+	return graph.depthFirstVisit(walker, state, root)
+}
+
+// walkError will translate the error returned from a walk to the error
+// returned to the caller of the walk.
+func walkError(err error) error {
+	if errors.Is(err, StopWalk) {
+		return nil
+	}
+	return err
 }
 
 // Structure holding information about the walk of an individual
@@ -152,11 +333,15 @@ func (walker *basicWalker) String() string {
 // called with the vertex and the time it was finished. Time in this
 // case is a logical clock that is stepped each time a new node is
 // discovered or finished.
-func (graph *Graph) DoDepthFirst(onDiscover, onFinish VertexWalkFunc) {
+//
+// If either function returns an error, the walk is aborted and the
+// error returned. The functions can return SkipChildren and StopWalk
+// to control the walk in the same way as for a walker.
+func (graph *Graph) DoDepthFirst(onDiscover, onFinish VertexWalkFunc) error {
 	walker := &basicWalker{
 		onDiscover: onDiscover,
 		onFinish:   onFinish,
 		info:       make(map[Vertex]*basicInfo),
 	}
-	graph.DepthFirstWalk(walker)
+	return graph.DepthFirstWalk(walker)
 }
@@ -6,6 +6,8 @@
 package directed
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -97,3 +99,346 @@ func TestCircularGraph(t *testing.T) {
 		return nil
 	}, nil)
 }
+
+// TestWalkError tests that an error returned from a walker aborts the
+// walk and is returned to the caller.
+func TestWalkError(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(3, 4)
+	failure := errors.New("failure")
+	count := 0
+	err := graph.DoDepthFirst(func(vertex Vertex) error {
+		count++
+		if count == 2 {
+			return failure
+		}
+		return nil
+	}, nil)
+	if err != failure {
+		t.Errorf("Wrong error returned: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Walk not aborted (%d vertices discovered)", count)
+	}
+
+	count = 0
+	err = graph.DoDepthFirst(func(vertex Vertex) error {
+		count++
+		if count == 2 {
+			return StopWalk
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Errorf("Error returned for StopWalk: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Walk not stopped (%d vertices discovered)", count)
+	}
+}
+
+// TestSkipChildren tests that returning SkipChildren prune the subtree
+// of a vertex, but that the vertex is still finished.
+func TestSkipChildren(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(1, 4)
+	graph.AddEdge(4, 5)
+	parent := map[Vertex]Vertex{}
+	finished := map[Vertex]bool{}
+	graph.DoDepthFirst(func(vertex Vertex) error {
+		if vertex == 2 {
+			return SkipChildren
+		}
+		return nil
+	}, func(vertex Vertex) error {
+		finished[vertex] = true
+		return nil
+	})
+	if len(finished) != 5 || !finished[2] {
+		t.Errorf("Vertices not finished: %v", finished)
+	}
+
+	// Vertex 3 is only reachable through vertex 2, so it has to be
+	// a root of the forest when the children of 2 are skipped.
+	walker := &parentWalker{parent: parent, skip: 2}
+	if err := graph.DepthFirstWalk(walker); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if parent[3] != nil {
+		t.Errorf("Wrong depth-first forest: %v", parent)
+	}
+}
+
+// TestWrappedSentinels tests that SkipChildren and StopWalk are
+// honoured also when wrapped in another error.
+func TestWrappedSentinels(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(1, 4)
+
+	walks := map[string]func(walker Walker) error{
+		"depth-first":   graph.DepthFirstWalk,
+		"breadth-first": graph.BreadthFirstWalk,
+	}
+	for name, walk := range walks {
+		parent := map[Vertex]Vertex{}
+		walker := &wrapWalker{parentWalker{parent: parent, skip: 2}}
+		if err := walk(walker); err != nil {
+			t.Errorf("%s: error returned: %v", name, err)
+		}
+		if parent[3] != nil {
+			t.Errorf("%s: children of 2 not skipped: %v", name, parent)
+		}
+
+		count := 0
+		err := walk(&fillableWalker{onDiscover: func(vertex Vertex) error {
+			count++
+			return fmt.Errorf("vertex %v: %w", vertex, StopWalk)
+		}})
+		if err != nil || count != 1 {
+			t.Errorf("%s: walk not stopped: %v after %d vertices", name, err, count)
+		}
+	}
+}
+
+// wrapWalker wraps the errors returned by a parentWalker.
+// treeSkipWalker records the parent of each discovered vertex and
+// skips some of the tree edges.
+type treeSkipWalker struct {
+	parentWalker
+	skip map[Edge]bool
+}
+
+func (walker *treeSkipWalker) OnTreeEdge(source, target Vertex) error {
+	if walker.skip[Edge{source, target}] {
+		return SkipChildren
+	}
+	return nil
+}
+
+func TestSkipTreeEdge(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(1, 3)
+	graph.AddEdge(3, 2)
+	graph.AddEdge(1, 4)
+
+	walks := map[string]func(walker Walker, roots ...Vertex) error{
+		"depth-first":   graph.DepthFirstWalkFrom,
+		"breadth-first": graph.BreadthFirstWalkFrom,
+	}
+	for name, walk := range walks {
+		parent := map[Vertex]Vertex{}
+		skip := map[Edge]bool{{1, 2}: true, {1, 4}: true}
+		walker := &treeSkipWalker{parentWalker{parent: parent}, skip}
+		if err := walk(walker, 1); err != nil {
+			t.Errorf("%s: error returned: %v", name, err)
+		}
+		if p, ok := parent[2]; !ok || p != 3 {
+			t.Errorf("%s: expected 2 to be discovered from 3, got %v", name, parent)
+		}
+		if _, ok := parent[4]; ok {
+			t.Errorf("%s: expected 4 not to be discovered, got %v", name, parent)
+		}
+	}
+}
+
+type wrapWalker struct {
+	parentWalker
+}
+
+func (walker *wrapWalker) OnDiscover(parent, vertex Vertex) error {
+	if err := walker.parentWalker.OnDiscover(parent, vertex); err != nil {
+		return fmt.Errorf("vertex %v: %w", vertex, err)
+	}
+	return nil
+}
+
+// parentWalker records the parent of each vertex in the depth-first
+// forest and skips the children of one vertex.
+type parentWalker struct {
+	DefaultWalker
+	parent map[Vertex]Vertex
+	skip   Vertex
+}
+
+func (walker *parentWalker) OnDiscover(parent, vertex Vertex) error {
+	walker.parent[vertex] = parent
+	if vertex == walker.skip {
+		return SkipChildren
+	}
+	return nil
+}
+
+// eventWalker records all the events of a walk as strings.
+type eventWalker struct {
+	events []string
+}
+
+func (walker *eventWalker) record(kind string, source, target Vertex) error {
+	walker.events = append(walker.events, fmt.Sprintf("%s %v %v", kind, source, target))
+	return nil
+}
+
+func (walker *eventWalker) OnDiscover(parent, vertex Vertex) error {
+	return walker.record("discover", parent, vertex)
+}
+
+func (walker *eventWalker) OnFinish(parent, vertex Vertex) error {
+	return walker.record("finish", parent, vertex)
+}
+
+func (walker *eventWalker) OnBackEdge(source, target Vertex) error {
+	return walker.record("back", source, target)
+}
+
+func (walker *eventWalker) OnCrossEdge(source, target Vertex) error {
+	return walker.record("cross", source, target)
+}
+
+// recursiveVisit is a straightforward recursive depth-first walk used
+// as reference for the order of events.
+func recursiveVisit(graph *Graph, walker Walker, info map[Vertex]uint8, parent, vertex Vertex) {
+	switch info[vertex] {
+	case WHITE:
+		info[vertex] = GREY
+		walker.OnDiscover(parent, vertex)
+		graph.DoOutEdges(vertex, func(source, target Vertex) error {
+			recursiveVisit(graph, walker, info, source, target)
+			return nil
+		})
+		info[vertex] = BLACK
+		walker.OnFinish(parent, vertex)
+	case GREY:
+		walker.OnBackEdge(parent, vertex)
+	case BLACK:
+		walker.OnCrossEdge(parent, vertex)
+	}
+}
+
+// TestEventOrder tests that the walk report events in the same order
+// as a recursive depth-first walk.
+func TestEventOrder(t *testing.T) {
+	graph := New()
+	for i := 0; i < 40; i++ {
+		graph.AddEdge(i%13, (i*7+3)%13)
+		graph.AddEdge(i%11, (i*5+1)%17)
+	}
+	for vertex := range graph.edges {
+		expected := &eventWalker{}
+		recursiveVisit(graph, expected, make(map[Vertex]uint8), nil, vertex)
+		actual := &eventWalker{}
+		graph.depthFirstVisit(actual, newDFSState(context.Background()), vertex)
+		if fmt.Sprint(expected.events) != fmt.Sprint(actual.events) {
+			t.Fatalf("Wrong order of events:\n%v\nexpected:\n%v", actual.events, expected.events)
+		}
+	}
+}
+
+// TestDeepGraph tests that a walk over a long path works.
+func TestDeepGraph(t *testing.T) {
+	graph := New()
+	for i := 0; i < 100000; i++ {
+		graph.AddEdge(i, i+1)
+	}
+	count := 0
+	graph.DoDepthFirst(nil, func(vertex Vertex) error {
+		count++
+		return nil
+	})
+	if count != graph.Order() {
+		t.Errorf("Wrong number of vertices finished (was %d, expected %d)", count, graph.Order())
+	}
+}
+
+// classifyWalker records the events of a walk, including tree and
+// forward edges.
+type classifyWalker struct {
+	eventWalker
+}
+
+func (walker *classifyWalker) OnTreeEdge(source, target Vertex) error {
+	return walker.record("tree", source, target)
+}
+
+func (walker *classifyWalker) OnForwardEdge(source, target Vertex) error {
+	return walker.record("forward", source, target)
+}
+
+func TestEdgeClassification(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(3, 1)
+	graph.AddEdge(1, 3)
+	graph.AddEdge(1, 4)
+	graph.AddEdge(4, 3)
+
+	walker := &classifyWalker{}
+	graph.depthFirstVisit(walker, newDFSState(context.Background()), 1)
+	expected := []string{
+		"discover <nil> 1",
+		"tree 1 2", "discover 1 2",
+		"tree 2 3", "discover 2 3",
+		"back 3 1",
+		"finish 2 3", "finish 1 2",
+		"forward 1 3",
+		"tree 1 4", "discover 1 4",
+		"cross 4 3",
+		"finish 1 4", "finish <nil> 1",
+	}
+	if fmt.Sprint(walker.events) != fmt.Sprint(expected) {
+		t.Errorf("Wrong events:\n%v\nexpected:\n%v", walker.events, expected)
+	}
+
+	// A walker not implementing EdgeWalker get forward edges
+	// reported as cross edges.
+	plain := &eventWalker{}
+	graph.depthFirstVisit(plain, newDFSState(context.Background()), 1)
+	if plain.events[6] != "cross 1 3" {
+		t.Errorf("Forward edge reported as %q", plain.events[6])
+	}
+}
+
+func TestDepthFirstWalkFrom(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(4, 3)
+	graph.AddEdge(5, 1)
+
+	walker := &eventWalker{}
+	graph.DepthFirstWalkFrom(walker, 2, 4, 6)
+	expected := []string{
+		"discover <nil> 2", "discover 2 3", "finish 2 3", "finish <nil> 2",
+		"discover <nil> 4", "cross 4 3", "finish <nil> 4",
+	}
+	if fmt.Sprint(walker.events) != fmt.Sprint(expected) {
+		t.Errorf("Wrong events:\n%v\nexpected:\n%v", walker.events, expected)
+	}
+
+	// Walking the entire graph twice should give the same result.
+	first := &eventWalker{}
+	graph.DepthFirstWalk(first)
+	second := &eventWalker{}
+	graph.DepthFirstWalk(second)
+	if fmt.Sprint(first.events) != fmt.Sprint(second.events) {
+		t.Errorf("Walks differ:\n%v\n%v", first.events, second.events)
+	}
+	if first.events[0] != "discover <nil> 1" {
+		t.Errorf("Walk not started at first vertex: %v", first.events[0])
+	}
+
+	sorted := &eventWalker{}
+	graph.DepthFirstWalkFrom(sorted, graph.SortedVertices(func(a, b Vertex) bool {
+		return a.(int) > b.(int)
+	})...)
+	if sorted.events[0] != "discover <nil> 5" {
+		t.Errorf("Walk not started at largest vertex: %v", sorted.events[0])
+	}
+}
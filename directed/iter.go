@@ -0,0 +1,113 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import "iter"
+
+// Vertices return an iterator over all the vertices of the graph, in
+// the order they were added to the graph.
+func (graph *Graph) Vertices() iter.Seq[Vertex] {
+	return func(yield func(Vertex) bool) {
+		for elem := graph.order.Front(); elem != nil; {
+			next := elem.Next()
+			if !yield(elem.Value) {
+				return
+			}
+			elem = next
+		}
+	}
+}
+
+// Edges return an iterator over all the edges of the graph, giving the
+// source and target vertex of each edge. The edges are given in the
+// same order as for DoEdges.
+func (graph *Graph) Edges() iter.Seq2[Vertex, Vertex] {
+	return func(yield func(Vertex, Vertex) bool) {
+		for source := range graph.Vertices() {
+			for source, target := range graph.OutEdges(source) {
+				if !yield(source, target) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// OutEdges return an iterator over the out-edges of a vertex, giving
+// the source and the target vertex of each edge.
+func (graph *Graph) OutEdges(vertex Vertex) iter.Seq2[Vertex, Vertex] {
+	return func(yield func(Vertex, Vertex) bool) {
+		lst := graph.edges[vertex]
+		if lst == nil {
+			return
+		}
+		for elem := lst.Front(); elem != nil; elem = elem.Next() {
+			if !yield(vertex, elem.Value) {
+				return
+			}
+		}
+	}
+}
+
+// yieldWalker is a walker that passes each vertex to a yield function
+// when it is discovered, and stops the walk when the yield function
+// returns false.
+type yieldWalker struct {
+	DefaultWalker
+	yield func(Vertex) bool
+}
+
+func (walker *yieldWalker) OnDiscover(parent, vertex Vertex) error {
+	if !walker.yield(vertex) {
+		return StopWalk
+	}
+	return nil
+}
+
+func (walker *yieldWalker) vertexOnly() {}
+
+// DFSPreorder return an iterator over the vertices reachable from
+// 'root' in depth-first preorder, that is, in the order they are
+// discovered by a depth-first walk.
+func (graph *Graph) DFSPreorder(root Vertex) iter.Seq[Vertex] {
+	return func(yield func(Vertex) bool) {
+		graph.DepthFirstWalkFrom(&yieldWalker{yield: yield}, root)
+	}
+}
+
+// BFS return an iterator over the vertices reachable from 'root' in
+// the order they are discovered by a breadth-first walk.
+func (graph *Graph) BFS(root Vertex) iter.Seq[Vertex] {
+	return func(yield func(Vertex) bool) {
+		graph.BreadthFirstWalkFrom(&yieldWalker{yield: yield}, root)
+	}
+}
+
+// TopologicalOrder return an iterator over the vertices of the graph
+// in topological order, that is, the same order as DoTopological.
+func (graph *Graph) TopologicalOrder() iter.Seq[Vertex] {
+	return func(yield func(Vertex) bool) {
+		graph.DoTopological(func(vertex Vertex) error {
+			if !yield(vertex) {
+				return StopWalk
+			}
+			return nil
+		})
+	}
+}
+
+// Components return an iterator over the weakly connected components
+// of the graph, in the same way as DoWeakComponents.
+func (graph *Graph) Components() iter.Seq[*Graph] {
+	return func(yield func(*Graph) bool) {
+		graph.DoWeakComponents(func(component *Graph) error {
+			if !yield(component) {
+				return StopWalk
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(1, 3)
+	graph.AddEdge(2, 4)
+	graph.AddEdge(3, 4)
+	graph.AddEdge(5, 6)
+
+	var vertices []Vertex
+	for vertex := range graph.Vertices() {
+		vertices = append(vertices, vertex)
+	}
+	if fmt.Sprint(vertices) != "[1 2 3 4 5 6]" {
+		t.Errorf("Wrong vertices: %v", vertices)
+	}
+
+	var edges []Edge
+	for source, target := range graph.Edges() {
+		edges = append(edges, Edge{source, target})
+		if len(edges) == 3 {
+			break
+		}
+	}
+	if fmt.Sprint(edges) != "[{1 2} {1 3} {2 4}]" {
+		t.Errorf("Wrong edges: %v", edges)
+	}
+
+	var targets []Vertex
+	for _, target := range graph.OutEdges(1) {
+		targets = append(targets, target)
+	}
+	if fmt.Sprint(targets) != "[2 3]" {
+		t.Errorf("Wrong out-edges: %v", targets)
+	}
+
+	collect := func(seq func(func(Vertex) bool), limit int) []Vertex {
+		var result []Vertex
+		for vertex := range seq {
+			result = append(result, vertex)
+			if len(result) == limit {
+				break
+			}
+		}
+		return result
+	}
+	if order := collect(graph.DFSPreorder(1), -1); fmt.Sprint(order) != "[1 2 4 3]" {
+		t.Errorf("Wrong depth-first order: %v", order)
+	}
+	if order := collect(graph.BFS(1), -1); fmt.Sprint(order) != "[1 2 3 4]" {
+		t.Errorf("Wrong breadth-first order: %v", order)
+	}
+	if order := collect(graph.BFS(1), 2); fmt.Sprint(order) != "[1 2]" {
+		t.Errorf("Wrong breadth-first order: %v", order)
+	}
+	if order := collect(graph.TopologicalOrder(), -1); fmt.Sprint(order) != "[5 6 1 3 2 4]" {
+		t.Errorf("Wrong topological order: %v", order)
+	}
+	if order := collect(graph.TopologicalOrder(), 1); fmt.Sprint(order) != "[5]" {
+		t.Errorf("Wrong topological order: %v", order)
+	}
+
+	count := 0
+	for component := range graph.Components() {
+		count++
+		if component.HasVertex(1) && component.Order() != 4 {
+			t.Errorf("Wrong component size: %d", component.Order())
+		}
+	}
+	if count != 2 {
+		t.Errorf("Wrong number of components: %d", count)
+	}
+}
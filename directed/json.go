@@ -0,0 +1,505 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// VertexCodec converts vertices to and from the node IDs used when a
+// graph is serialized. Since the ID is a string, a codec is needed to
+// serialize graphs where the vertices are not strings.
+type VertexCodec interface {
+	EncodeVertex(vertex Vertex) (string, error)
+	DecodeVertex(id string) (Vertex, error)
+}
+
+// StringCodec is a vertex codec for graphs where the vertices are
+// strings. It is the default codec.
+type StringCodec struct{}
+
+func (StringCodec) EncodeVertex(vertex Vertex) (string, error) {
+	if id, ok := vertex.(string); ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("vertex %#v is not a string", vertex)
+}
+
+func (StringCodec) DecodeVertex(id string) (Vertex, error) {
+	return id, nil
+}
+
+// IntCodec is a vertex codec for graphs where the vertices are of
+// type int.
+type IntCodec struct{}
+
+func (IntCodec) EncodeVertex(vertex Vertex) (string, error) {
+	if value, ok := vertex.(int); ok {
+		return strconv.Itoa(value), nil
+	}
+	return "", fmt.Errorf("vertex %#v is not an int", vertex)
+}
+
+func (IntCodec) DecodeVertex(id string) (Vertex, error) {
+	return strconv.Atoi(id)
+}
+
+// orDefault will return the codec, or StringCodec if it is nil.
+func orDefault(codec VertexCodec) VertexCodec {
+	if codec == nil {
+		return StringCodec{}
+	}
+	return codec
+}
+
+// MarshalJSON will encode the graph in the JSON Graph Format (version
+// 2), using StringCodec to produce the node IDs. Use WithCodec to
+// marshal graphs where the vertices are not strings.
+func (graph *Graph) MarshalJSON() ([]byte, error) {
+	return graph.WithCodec(nil).MarshalJSON()
+}
+
+// UnmarshalJSON will decode a graph in the JSON Graph Format, using
+// StringCodec to decode the node IDs. Any vertices and edges already
+// in the graph are removed. If the document contains several graphs,
+// only the first one is decoded. Each edge of an undirected graph is
+// added in both directions; use WithCodec to tell if the graph was
+// undirected.
+func (graph *Graph) UnmarshalJSON(data []byte) error {
+	return graph.WithCodec(nil).UnmarshalJSON(data)
+}
+
+// CodecGraph is a graph together with the vertex codec used when it
+// is marshalled to or unmarshalled from JSON, or written to or read
+// from a binary snapshot. A nil codec is the same as StringCodec.
+type CodecGraph struct {
+	*Graph
+	Codec VertexCodec
+
+	// Undirected will, if true, marshal the graph as undirected in
+	// the same way as Encoder.SetUndirected. It is set when
+	// unmarshalling to tell if the graph was undirected.
+	Undirected bool
+}
+
+// WithCodec will return the graph together with a vertex codec, for
+// example, to marshal a graph where the vertices are ints:
+//
+//	data, err := json.Marshal(graph.WithCodec(directed.IntCodec{}))
+func (graph *Graph) WithCodec(codec VertexCodec) *CodecGraph {
+	return &CodecGraph{Graph: graph, Codec: codec}
+}
+
+// MarshalJSON will encode the graph in the JSON Graph Format (version
+// 2), using the codec to produce the node IDs.
+func (graph *CodecGraph) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetVertexCodec(graph.Codec)
+	enc.SetUndirected(graph.Undirected)
+	if err := enc.Encode(graph.Graph); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// UnmarshalJSON will decode a graph in the JSON Graph Format in the
+// same way as Graph.UnmarshalJSON, but using the codec to decode the
+// node IDs.
+func (graph *CodecGraph) UnmarshalJSON(data []byte) error {
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.SetVertexCodec(graph.Codec)
+	if err := dec.Decode(graph.Graph); err != nil {
+		return err
+	}
+	graph.Undirected = !dec.Directed()
+	return nil
+}
+
+// Encoder writes graphs in the JSON Graph Format to an output stream.
+type Encoder struct {
+	out        io.Writer
+	codec      VertexCodec
+	undirected bool
+}
+
+// NewEncoder will create a new encoder writing to 'out'.
+func NewEncoder(out io.Writer) *Encoder {
+	return &Encoder{out: out}
+}
+
+// SetVertexCodec will set the codec used to encode the node IDs.
+// Passing nil restores the default codec, which is StringCodec.
+func (enc *Encoder) SetVertexCodec(codec VertexCodec) {
+	enc.codec = codec
+}
+
+// SetUndirected will, if true, make the encoder write graphs as
+// undirected. Each pair of edges in opposite directions is then
+// written as a single edge, while edges without a reverse edge are
+// written with "directed" set to true, so that the decoder gives back
+// the same edges.
+func (enc *Encoder) SetUndirected(undirected bool) {
+	enc.undirected = undirected
+}
+
+// Encode will write the graph to the stream as a single JSON document
+// followed by a newline. The vertices and edges are written one at a
+// time in the order of the graph, so no representation of the entire
+// document is built in memory. When writing undirected graphs, the
+// edges written are recorded to write each pair of edges only once.
+func (enc *Encoder) Encode(graph *Graph) error {
+	codec := orDefault(enc.codec)
+	ids := make(map[Vertex][]byte, graph.Order())
+	buf := bufio.NewWriter(enc.out)
+	fmt.Fprintf(buf, `{"graph":{"directed":%t,"nodes":{`, !enc.undirected)
+	first := true
+	err := graph.DoVertices(func(vertex Vertex) error {
+		id, err := codec.EncodeVertex(vertex)
+		if err != nil {
+			return err
+		}
+		quoted, err := json.Marshal(id)
+		if err != nil {
+			return err
+		}
+		ids[vertex] = quoted
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(quoted)
+		buf.WriteString(":{}")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	buf.WriteString(`},"edges":[`)
+	first = true
+	written := make(map[Edge]bool)
+	graph.DoEdges(func(source, target Vertex) error {
+		if enc.undirected {
+			if written[Edge{target, source}] {
+				return nil
+			}
+			written[Edge{source, target}] = true
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteString(`{"source":`)
+		buf.Write(ids[source])
+		buf.WriteString(`,"target":`)
+		buf.Write(ids[target])
+		if enc.undirected && !graph.HasEdge(target, source) {
+			buf.WriteString(`,"directed":true`)
+		}
+		buf.WriteByte('}')
+		return nil
+	})
+	buf.WriteString("]}}\n")
+	return buf.Flush()
+}
+
+// Decoder reads graphs in the JSON Graph Format from an input stream.
+//
+// The stream is read one token at a time and the graph is built while
+// reading, so large documents can be decoded without first reading
+// the entire document into memory. The stream can contain several
+// documents, each containing either a single graph or a list of
+// graphs. Both the object form of the nodes used by version 2 and the
+// array form used by version 1 of the format are accepted.
+type Decoder struct {
+	dec   *json.Decoder
+	codec VertexCodec
+
+	// inList is true while decoding the graphs of a "graphs"
+	// list.
+	inList bool
+
+	// directed is false if the last graph decoded was undirected.
+	directed bool
+}
+
+// NewDecoder will create a new decoder reading from 'in'.
+func NewDecoder(in io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(in)}
+}
+
+// SetVertexCodec will set the codec used to decode the node IDs.
+// Passing nil restores the default codec, which is StringCodec.
+func (dec *Decoder) SetVertexCodec(codec VertexCodec) {
+	dec.codec = codec
+}
+
+// Directed will return false if the last graph decoded was
+// undirected, in which case it can be encoded again using an encoder
+// where SetUndirected has been called.
+func (dec *Decoder) Directed() bool {
+	return dec.directed
+}
+
+// expect will read the next token and check that it is the given
+// delimiter.
+func (dec *Decoder) expect(delim json.Delim) error {
+	tok, err := dec.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("json graph: expected %v, found %v", delim, tok)
+	}
+	return nil
+}
+
+// key will read the key of the next member of an object, or return
+// false if the end of the object was reached.
+func (dec *Decoder) key() (string, bool, error) {
+	if !dec.dec.More() {
+		_, err := dec.dec.Token()
+		return "", false, err
+	}
+	tok, err := dec.dec.Token()
+	if err != nil {
+		return "", false, err
+	}
+	return tok.(string), true, nil
+}
+
+// skip will skip the next value.
+func (dec *Decoder) skip() error {
+	var value json.RawMessage
+	return dec.dec.Decode(&value)
+}
+
+// Decode will read the next graph from the stream into 'graph',
+// replacing any vertices and edges already in the graph. It returns
+// io.EOF when there are no more graphs. If the graph is undirected,
+// each edge is added in both directions.
+func (dec *Decoder) Decode(graph *Graph) error {
+	for {
+		if dec.inList {
+			if dec.dec.More() {
+				return dec.decodeGraph(graph)
+			}
+			// End of the list, and the rest of the document.
+			dec.inList = false
+			if err := dec.expect(']'); err != nil {
+				return err
+			}
+			if err := dec.finishDocument(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Start of a new document.
+		tok, err := dec.dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok != json.Delim('{') {
+			return fmt.Errorf("json graph: expected object, found %v", tok)
+		}
+		for !dec.inList {
+			name, more, err := dec.key()
+			if err != nil {
+				return err
+			}
+			if !more {
+				return fmt.Errorf("json graph: no graph in document")
+			}
+			switch name {
+			case "graph":
+				if err := dec.decodeGraph(graph); err != nil {
+					return err
+				}
+				return dec.finishDocument()
+			case "graphs":
+				if err := dec.expect('['); err != nil {
+					return err
+				}
+				dec.inList = true
+			default:
+				if err := dec.skip(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// finishDocument will skip the remaining members of the top-level
+// object of a document.
+func (dec *Decoder) finishDocument() error {
+	for {
+		_, more, err := dec.key()
+		if err != nil || !more {
+			return err
+		}
+		if err := dec.skip(); err != nil {
+			return err
+		}
+	}
+}
+
+// jsonEdge is an edge of the JSON Graph Format.
+type jsonEdge struct {
+	Source   *string `json:"source"`
+	Target   *string `json:"target"`
+	Directed *bool   `json:"directed"`
+}
+
+// decodeGraph will decode a single graph object.
+func (dec *Decoder) decodeGraph(graph *Graph) error {
+	codec := orDefault(dec.codec)
+	*graph = Graph{
+		edges:    make(adjacencyList),
+		order:    list.New(),
+		position: make(map[Vertex]*list.Element),
+	}
+
+	decodeVertex := func(id string) (Vertex, error) {
+		vertex, err := codec.DecodeVertex(id)
+		if err != nil {
+			return nil, fmt.Errorf("json graph: bad node ID %q: %v", id, err)
+		}
+		return vertex, nil
+	}
+
+	directed := true
+	explicit := make(map[Edge]bool)
+	if err := dec.expect('{'); err != nil {
+		return err
+	}
+	for {
+		name, more, err := dec.key()
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+		switch name {
+		case "directed":
+			if err := dec.dec.Decode(&directed); err != nil {
+				return err
+			}
+
+		case "nodes":
+			tok, err := dec.dec.Token()
+			if err != nil {
+				return err
+			}
+			switch tok {
+			case json.Delim('{'):
+				for {
+					id, more, err := dec.key()
+					if err != nil {
+						return err
+					}
+					if !more {
+						break
+					}
+					vertex, err := decodeVertex(id)
+					if err != nil {
+						return err
+					}
+					graph.AddVertex(vertex)
+					if err := dec.skip(); err != nil {
+						return err
+					}
+				}
+			case json.Delim('['):
+				for dec.dec.More() {
+					var node struct {
+						ID *string `json:"id"`
+					}
+					if err := dec.dec.Decode(&node); err != nil {
+						return err
+					}
+					if node.ID == nil {
+						return fmt.Errorf("json graph: node without ID")
+					}
+					vertex, err := decodeVertex(*node.ID)
+					if err != nil {
+						return err
+					}
+					graph.AddVertex(vertex)
+				}
+				if err := dec.expect(']'); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("json graph: expected nodes, found %v", tok)
+			}
+
+		case "edges":
+			if err := dec.expect('['); err != nil {
+				return err
+			}
+			for dec.dec.More() {
+				var edge jsonEdge
+				if err := dec.dec.Decode(&edge); err != nil {
+					return err
+				}
+				if edge.Source == nil || edge.Target == nil {
+					return fmt.Errorf("json graph: edge without source or target")
+				}
+				source, err := decodeVertex(*edge.Source)
+				if err != nil {
+					return err
+				}
+				target, err := decodeVertex(*edge.Target)
+				if err != nil {
+					return err
+				}
+				graph.AddEdge(source, target)
+				if edge.Directed != nil {
+					if *edge.Directed {
+						explicit[Edge{source, target}] = true
+					} else {
+						graph.AddEdge(target, source)
+					}
+				}
+			}
+			if err := dec.expect(']'); err != nil {
+				return err
+			}
+
+		default:
+			if err := dec.skip(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Since the direction of the graph can be given after the
+	// edges, the reverse edges of an undirected graph are added
+	// last.
+	dec.directed = directed
+	if !directed {
+		var edges []Edge
+		graph.DoEdges(func(source, target Vertex) error {
+			if !explicit[Edge{source, target}] {
+				edges = append(edges, Edge{source, target})
+			}
+			return nil
+		})
+		for _, edge := range edges {
+			graph.AddEdge(edge.Target, edge.Source)
+		}
+	}
+	return nil
+}
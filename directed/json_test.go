@@ -0,0 +1,182 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func edgeString(graph *Graph) string {
+	var edges []string
+	graph.DoEdges(func(source, target Vertex) error {
+		edges = append(edges, fmt.Sprintf("%v->%v", source, target))
+		return nil
+	})
+	return strings.Join(edges, " ")
+}
+
+func TestMarshalJSON(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", `"c"`)
+	graph.AddVertex("d")
+
+	data, err := json.Marshal(graph)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `{"graph":{"directed":true,"nodes":{"a":{},"b":{},"\"c\"":{},"d":{}},` +
+		`"edges":[{"source":"a","target":"b"},{"source":"b","target":"\"c\""}]}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	result := New()
+	if err := json.Unmarshal(data, result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	checkGraphCount(t, result, 4, 2)
+	if got := edgeString(result); got != edgeString(graph) {
+		t.Errorf("Expected edges %s, got %s", edgeString(graph), got)
+	}
+	var order []Vertex
+	result.DoVertices(func(vertex Vertex) error {
+		order = append(order, vertex)
+		return nil
+	})
+	if fmt.Sprint(order) != `[a b "c" d]` {
+		t.Errorf("Wrong vertex order %v", order)
+	}
+}
+
+func TestVertexCodec(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	if _, err := json.Marshal(graph); err == nil {
+		t.Errorf("Expected error when marshalling int vertices as strings")
+	}
+
+	data, err := json.Marshal(graph.WithCodec(IntCodec{}))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	// A zero graph can be used as target.
+	var result Graph
+	if err := json.Unmarshal(data, result.WithCodec(IntCodec{})); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !result.HasEdge(1, 2) || !result.HasEdge(2, 3) || result.Size() != 2 {
+		t.Errorf("Wrong edges: %s", edgeString(&result))
+	}
+
+	var bad Graph
+	if err := json.Unmarshal([]byte(`{"graph":{"nodes":{"x":{}}}}`), bad.WithCodec(IntCodec{})); err == nil {
+		t.Errorf("Expected error for bad node ID")
+	}
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetVertexCodec(IntCodec{})
+	if err := enc.Encode(graph); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != string(data) {
+		t.Errorf("Expected %s, got %s", data, buf.String())
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	input := `
+{"graph": {"label": "v1", "nodes": [{"id": "a"}, {"id": "b", "metadata": {"x": [1, 2]}}],
+           "edges": [{"source": "a", "target": "b", "relation": "r"}]}}
+{"graphs": [
+  {"edges": [{"source": "a", "target": "b"},
+             {"source": "b", "target": "c", "directed": true}],
+   "directed": false},
+  {"nodes": {"x": {"label": "X"}}, "edges": []}
+], "metadata": {}}
+`
+	expected := []string{
+		"a->b",
+		"a->b b->c b->a",
+		"",
+	}
+	dec := NewDecoder(strings.NewReader(input))
+	for i, edges := range expected {
+		graph := New()
+		graph.AddEdge("old", "edge")
+		if err := dec.Decode(graph); err != nil {
+			t.Fatalf("Decode %d failed: %v", i, err)
+		}
+		if got := edgeString(graph); got != edges {
+			t.Errorf("Expected edges %q, got %q", edges, got)
+		}
+	}
+	if err := dec.Decode(New()); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+}
+
+func TestUndirected(t *testing.T) {
+	input := `{"graph":{"directed":false,"nodes":{"a":{},"b":{},"c":{}},` +
+		`"edges":[{"source":"a","target":"b"},{"source":"b","target":"c","directed":true}]}}`
+	dec := NewDecoder(strings.NewReader(input))
+	graph := New()
+	if err := dec.Decode(graph); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if dec.Directed() {
+		t.Errorf("Expected undirected graph")
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetUndirected(true)
+	if err := enc.Encode(graph); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != input {
+		t.Errorf("Expected %s, got %s", input, got)
+	}
+
+	// The direction is kept when using json.Unmarshal and
+	// json.Marshal.
+	var result Graph
+	codecGraph := result.WithCodec(nil)
+	if err := json.Unmarshal([]byte(input), codecGraph); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	data, err := json.Marshal(codecGraph)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !codecGraph.Undirected || string(data) != input {
+		t.Errorf("Expected %s, got %s", input, data)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []string{
+		`[]`,
+		`{"other": 1}`,
+		`{"graph": []}`,
+		`{"graph": {"nodes": 1}}`,
+		`{"graph": {"nodes": [{"label": "no id"}]}}`,
+		`{"graph": {"edges": [{"source": "a"}]}}`,
+		`{"graph": {"edges": [`,
+	}
+	for _, input := range tests {
+		if err := NewDecoder(bytes.NewBufferString(input)).Decode(New()); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/mkindahl/gograph/djs"
+)
+
+// weightedEdge is an edge together with its weight, used when
+// ordering edges by weight.
+type weightedEdge struct {
+	Edge
+	weight float64
+}
+
+// edgeHeap is a priority queue of edges with the lightest edge on
+// top. It implements heap.Interface.
+type edgeHeap []weightedEdge
+
+func (h edgeHeap) Len() int            { return len(h) }
+func (h edgeHeap) Less(i, j int) bool  { return h[i].weight < h[j].weight }
+func (h edgeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *edgeHeap) Push(x interface{}) { *h = append(*h, x.(weightedEdge)) }
+
+func (h *edgeHeap) Pop() interface{} {
+	old := *h
+	edge := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return edge
+}
+
+// KruskalSpanningForest will compute a minimum spanning forest of the
+// graph using Kruskal's algorithm. The graph is treated as
+// undirected, that is, the direction of the edges is ignored, and
+// the weight of each edge is given by 'weight'. Self-loops are never
+// part of the forest.
+//
+// The forest is returned as a new graph containing all the vertices
+// of the graph and the edges picked for the forest, in the same
+// direction as in the original graph, together with the total weight
+// of the forest.
+func (graph *Graph) KruskalSpanningForest(weight WeightFunc) (*Graph, float64) {
+	forest := New()
+	sets := djs.New()
+	graph.DoVertices(func(vertex Vertex) error {
+		forest.AddVertex(vertex)
+		sets.MakeSet(vertex)
+		return nil
+	})
+
+	edges := make([]weightedEdge, 0, graph.Size())
+	graph.DoEdges(func(source, target Vertex) error {
+		if source != target {
+			edge := weightedEdge{Edge{source, target}, weight(source, target)}
+			edges = append(edges, edge)
+		}
+		return nil
+	})
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].weight < edges[j].weight
+	})
+
+	total := 0.0
+	for _, edge := range edges {
+		if sets.Find(edge.Source) != sets.Find(edge.Target) {
+			sets.Union(edge.Source, edge.Target)
+			forest.AddEdge(edge.Source, edge.Target)
+			total += edge.weight
+		}
+	}
+	return forest, total
+}
+
+// PrimSpanningForest will compute a minimum spanning forest of the
+// graph using Prim's algorithm. The graph is treated as undirected
+// and the result is the same as for KruskalSpanningForest, but the
+// forest is grown one tree at a time from a root vertex, using a heap
+// to pick the lightest edge leaving the tree.
+func (graph *Graph) PrimSpanningForest(weight WeightFunc) (*Graph, float64) {
+	// Collect both the out-edges and the in-edges of each vertex
+	// since the graph should be treated as undirected.
+	incident := make(map[Vertex][]weightedEdge)
+	graph.DoEdges(func(source, target Vertex) error {
+		if source != target {
+			edge := weightedEdge{Edge{source, target}, weight(source, target)}
+			incident[source] = append(incident[source], edge)
+			incident[target] = append(incident[target], edge)
+		}
+		return nil
+	})
+
+	forest := New()
+	total := 0.0
+	queue := &edgeHeap{}
+	graph.DoVertices(func(root Vertex) error {
+		if !forest.AddVertex(root) {
+			return nil
+		}
+		for _, edge := range incident[root] {
+			heap.Push(queue, edge)
+		}
+		for queue.Len() > 0 {
+			edge := heap.Pop(queue).(weightedEdge)
+			// Pick the endpoint that is not in the tree, if
+			// there is one.
+			next := edge.Target
+			if forest.HasVertex(next) {
+				next = edge.Source
+			}
+			if forest.HasVertex(next) {
+				continue
+			}
+			forest.AddEdge(edge.Source, edge.Target)
+			total += edge.weight
+			for _, other := range incident[next] {
+				if !forest.HasVertex(other.Source) || !forest.HasVertex(other.Target) {
+					heap.Push(queue, other)
+				}
+			}
+		}
+		return nil
+	})
+	return forest, total
+}
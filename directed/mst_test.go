@@ -0,0 +1,65 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import "testing"
+
+// Example graph from the book by Cormen et.al. with the edges given
+// an arbitrary direction, and an additional component consisting of
+// the vertices "x", "y" and "z".
+func mstExample() (*Graph, WeightFunc) {
+	weights := map[Edge]float64{
+		{"a", "b"}: 4, {"a", "h"}: 8, {"b", "c"}: 8, {"b", "h"}: 11,
+		{"c", "d"}: 7, {"c", "f"}: 4, {"c", "i"}: 2, {"d", "e"}: 9,
+		{"d", "f"}: 14, {"e", "f"}: 10, {"f", "g"}: 2, {"g", "h"}: 1,
+		{"g", "i"}: 6, {"h", "i"}: 7,
+		{"x", "y"}: 3, {"z", "y"}: 1, {"x", "z"}: 2, {"z", "z"}: 0,
+	}
+	graph := New()
+	for edge := range weights {
+		graph.AddEdge(edge.Source, edge.Target)
+	}
+	return graph, func(source, target Vertex) float64 {
+		return weights[Edge{source, target}]
+	}
+}
+
+func checkSpanningForest(t *testing.T, name string, graph, forest *Graph, total float64) {
+	if total != 40 {
+		t.Errorf("%s: wrong total weight (was %v, expected %v)", name, total, 40)
+	}
+	if forest.Order() != graph.Order() {
+		t.Errorf("%s: wrong number of vertices (was %d, expected %d)",
+			name, forest.Order(), graph.Order())
+	}
+	// Two components, so there should be two edges less than
+	// vertices.
+	if forest.Size() != graph.Order()-2 {
+		t.Errorf("%s: wrong number of edges (was %d, expected %d)",
+			name, forest.Size(), graph.Order()-2)
+	}
+	forest.DoEdges(func(source, target Vertex) error {
+		if !graph.HasEdge(source, target) {
+			t.Errorf("%s: edge %v -> %v not in graph", name, source, target)
+		}
+		return nil
+	})
+	if forest.HasEdge("x", "y") || forest.HasEdge("z", "z") {
+		t.Errorf("%s: heavy edge in forest", name)
+	}
+}
+
+func TestKruskalSpanningForest(t *testing.T) {
+	graph, weight := mstExample()
+	forest, total := graph.KruskalSpanningForest(weight)
+	checkSpanningForest(t, "Kruskal", graph, forest, total)
+}
+
+func TestPrimSpanningForest(t *testing.T) {
+	graph, weight := mstExample()
+	forest, total := graph.PrimSpanningForest(weight)
+	checkSpanningForest(t, "Prim", graph, forest, total)
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Parameters for switching between top-down and bottom-up steps in
+// the direction-optimizing breadth-first search, as suggested by
+// Beamer, Asanović, and Patterson.
+const (
+	bfsAlpha = 14
+	bfsBeta  = 24
+)
+
+// bitmap is a set of vertex indexes.
+type bitmap []uint64
+
+func newBitmap(size int) bitmap {
+	return make(bitmap, (size+63)/64)
+}
+
+func (bm bitmap) set(i int32) {
+	bm[i/64] |= 1 << (uint(i) % 64)
+}
+
+func (bm bitmap) has(i int32) bool {
+	return bm[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// parallelSearch is the state of a parallel breadth-first search.
+type parallelSearch struct {
+	compact  *Compact
+	workers  int
+	parent   []int32
+	distance []int32
+}
+
+// parallel will call 'work' with 'workers' ranges splitting [0,n)
+// and wait for all of them to complete. The ranges are aligned to
+// multiples of 64, so that each worker can update its own words of a
+// bitmap without synchronization.
+func parallel(n, workers int, work func(worker, low, high int)) {
+	chunk := (n + workers - 1) / workers
+	chunk = (chunk + 63) / 64 * 64
+	var wg sync.WaitGroup
+	for worker, low := 0, 0; low < n; worker, low = worker+1, low+chunk {
+		high := low + chunk
+		if high > n {
+			high = n
+		}
+		wg.Add(1)
+		go func(worker, low, high int) {
+			defer wg.Done()
+			work(worker, low, high)
+		}(worker, low, high)
+	}
+	wg.Wait()
+}
+
+// topDown will expand the frontier by following the out-edges of all
+// vertices in the frontier. Vertices are claimed using an atomic
+// compare-and-swap of the parent, so each vertex is added to the next
+// frontier exactly once.
+func (search *parallelSearch) topDown(frontier []int32, level int32) []int32 {
+	found := make([][]int32, search.workers)
+	parallel(len(frontier), search.workers, func(worker, low, high int) {
+		var next []int32
+		for _, u := range frontier[low:high] {
+			for _, v := range search.compact.OutEdges(int(u)) {
+				if atomic.LoadInt32(&search.parent[v]) < 0 &&
+					atomic.CompareAndSwapInt32(&search.parent[v], -1, u) {
+					search.distance[v] = level + 1
+					next = append(next, v)
+				}
+			}
+		}
+		found[worker] = next
+	})
+	var next []int32
+	for _, vertices := range found {
+		next = append(next, vertices...)
+	}
+	return next
+}
+
+// bottomUp will expand the frontier by letting each vertex not yet
+// reached look for a parent in the frontier among the sources of its
+// in-edges. Each worker only writes to its own range of vertices, so
+// no synchronization is necessary. The number of vertices in the new
+// frontier and the number of out-edges from them are also returned.
+func (search *parallelSearch) bottomUp(frontier bitmap, level int32) (bitmap, int, int) {
+	next := newBitmap(len(search.parent))
+	counts := make([]int, search.workers)
+	edges := make([]int, search.workers)
+	parallel(len(search.parent), search.workers, func(worker, low, high int) {
+		for v := low; v < high; v++ {
+			if search.parent[v] >= 0 {
+				continue
+			}
+			for _, u := range search.compact.InEdges(v) {
+				if frontier.has(u) {
+					search.parent[v] = u
+					search.distance[v] = level + 1
+					next.set(int32(v))
+					counts[worker]++
+					edges[worker] += len(search.compact.OutEdges(v))
+					break
+				}
+			}
+		}
+	})
+	count, edgeCount := 0, 0
+	for worker := range counts {
+		count += counts[worker]
+		edgeCount += edges[worker]
+	}
+	return next, count, edgeCount
+}
+
+// toBitmap will convert a frontier given as a list of vertices to a
+// bitmap.
+func (search *parallelSearch) toBitmap(frontier []int32) bitmap {
+	bm := newBitmap(len(search.parent))
+	for _, v := range frontier {
+		bm.set(v)
+	}
+	return bm
+}
+
+// toList will convert a frontier given as a bitmap to a list of
+// vertices.
+func toList(bm bitmap) []int32 {
+	var frontier []int32
+	for i, word := range bm {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			frontier = append(frontier, int32(i*64+bit))
+			word &= word - 1
+		}
+	}
+	return frontier
+}
+
+// ParallelBFS will perform a breadth-first search from the vertex with
+// index 'source' using 'workers' goroutines, or one per available CPU
+// if 'workers' is not positive.
+//
+// The search is direction-optimizing: while the frontier is small, it
+// follows the out-edges of the frontier (top-down), and when the
+// frontier grows large, it instead lets each unreached vertex search
+// its in-edges for a vertex in the frontier (bottom-up), which
+// examines far fewer edges for graphs with small diameter.
+//
+// The result is the parent of each vertex in the breadth-first tree
+// and the distance of each vertex from the source, both indexed by
+// vertex index. The parent of the source is the source itself, and
+// vertices that are not reachable have parent and distance -1. Since
+// the search is parallel, the parent of a vertex can be any vertex on
+// the previous level with an edge to the vertex.
+func (compact *Compact) ParallelBFS(source int, workers int) (parent []int32, distance []int32) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	n := compact.Order()
+	search := &parallelSearch{
+		compact:  compact,
+		workers:  workers,
+		parent:   make([]int32, n),
+		distance: make([]int32, n),
+	}
+	for i := range search.parent {
+		search.parent[i] = -1
+		search.distance[i] = -1
+	}
+	if source < 0 || source >= n {
+		return search.parent, search.distance
+	}
+	search.parent[source] = int32(source)
+	search.distance[source] = 0
+
+	// The number of edges to check from the frontier and from the
+	// unexplored vertices decide the direction of each step.
+	frontier := []int32{int32(source)}
+	unexplored := compact.Size() - len(compact.OutEdges(source))
+	for level := int32(0); len(frontier) > 0; level++ {
+		frontierEdges := 0
+		for _, v := range frontier {
+			frontierEdges += len(compact.OutEdges(int(v)))
+		}
+		if frontierEdges > unexplored/bfsAlpha {
+			// Do bottom-up steps until the frontier is small
+			// again.
+			bm := search.toBitmap(frontier)
+			for {
+				next, count, edges := search.bottomUp(bm, level)
+				bm = next
+				unexplored -= edges
+				if count == 0 || count < n/bfsBeta {
+					break
+				}
+				level++
+			}
+			frontier = toList(bm)
+		} else {
+			frontier = search.topDown(frontier, level)
+			for _, v := range frontier {
+				unexplored -= len(compact.OutEdges(int(v)))
+			}
+		}
+	}
+	return search.parent, search.distance
+}
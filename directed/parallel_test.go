@@ -0,0 +1,66 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func checkParallelBFS(t *testing.T, graph *Graph, source Vertex, workers int) {
+	compact := graph.Compact()
+	expected := graph.Distances(-1, source)
+	index, _ := compact.Index(source)
+	parent, distance := compact.ParallelBFS(index, workers)
+	for i := 0; i < compact.Order(); i++ {
+		vertex := compact.Vertex(i)
+		want, ok := expected[vertex]
+		if !ok {
+			if distance[i] != -1 || parent[i] != -1 {
+				t.Errorf("Unreachable vertex %v reached", vertex)
+			}
+			continue
+		}
+		if int(distance[i]) != want {
+			t.Errorf("Wrong distance to %v (was %d, expected %d)", vertex, distance[i], want)
+		}
+		if i == index {
+			if parent[i] != int32(i) {
+				t.Errorf("Wrong parent of source: %d", parent[i])
+			}
+			continue
+		}
+		p := int(parent[i])
+		if !graph.HasEdge(compact.Vertex(p), vertex) || distance[p] != distance[i]-1 {
+			t.Errorf("Wrong parent %v of %v", compact.Vertex(p), vertex)
+		}
+	}
+}
+
+func TestParallelBFS(t *testing.T) {
+	random := rand.New(rand.NewSource(17))
+	for _, size := range []int{1, 10, 1000} {
+		for _, degree := range []int{1, 4, 16} {
+			graph := New()
+			for v := 0; v < size; v++ {
+				graph.AddVertex(v)
+			}
+			for e := 0; e < size*degree; e++ {
+				graph.AddEdge(random.Intn(size), random.Intn(size))
+			}
+			for _, workers := range []int{0, 1, 3, 8} {
+				checkParallelBFS(t, graph, 0, workers)
+			}
+		}
+	}
+
+	graph := New()
+	graph.AddEdge(1, 2)
+	parent, distance := graph.Compact().ParallelBFS(5, 2)
+	if parent[0] != -1 || distance[1] != -1 {
+		t.Errorf("Vertices reached from invalid source")
+	}
+}
@@ -5,6 +5,7 @@
 
 package directed
 import "errors"
+import "context"
 import "container/list"
 
 type shortestPathWalker struct { 
@@ -23,7 +24,7 @@ func (spw *shortestPathWalker) OnDiscover(parent, vertex Vertex) error {
 func (spw *shortestPathWalker) OnFinish(parent, vertex Vertex) error {
 	spw.childOf[vertex] = parent
 	if vertex == spw.targetVertex {
-		return errors.New("Found node")
+		return StopWalk
 	}
 	return nil
 }
@@ -36,17 +37,33 @@ func (spw *shortestPathWalker) OnCrossEdge(parent, vertex Vertex) error {
 	return nil
 }
 
+func (spw *shortestPathWalker) vertexOnly() {}
+
 // Finds the shortest path between two vertices, if such a path exists. The list
 // returned will be either nil if no path was found (in which case, error will be set) or
 // a list of vertices starting with start and ending with stop. This is implemented using BFS
 // and has complexity O(|E|)
 func (graph *Graph) FindShortestPath(start, stop Vertex) (*list.List, error) {
+	return graph.FindShortestPathContext(context.Background(), start, stop)
+}
+
+// FindShortestPathContext finds the shortest path between two vertices
+// in the same way as FindShortestPath, but the search is aborted and
+// the error of the context returned if the context is cancelled.
+func (graph *Graph) FindShortestPathContext(ctx context.Context, start, stop Vertex) (*list.List, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	w := new(shortestPathWalker)
 	w.Init()
 	w.targetVertex = stop
 
 	toR := list.New()
-	graph.BreadthFirstWalkFromVertex(w, start)
+	seen := make(map[Vertex]uint8)
+	err := walkError(graph.breadthFirstVisit(w, seen, newCanceller(ctx), start))
+	if err != nil {
+		return nil, err
+	}
 
 	last := stop
 	for ; ; {
@@ -0,0 +1,310 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import "sort"
+
+// Descendants will return all vertices reachable from 'vertex' using
+// at least one edge, in breadth-first order. The vertex itself is only
+// included if it is on a cycle.
+func (graph *Graph) Descendants(vertex Vertex) []Vertex {
+	return reachFrom(vertex, func(vertex Vertex, fn func(Vertex)) {
+		graph.DoOutEdges(vertex, func(source, target Vertex) error {
+			fn(target)
+			return nil
+		})
+	})
+}
+
+// Ancestors will return all vertices from which 'vertex' is reachable
+// using at least one edge, in breadth-first order following the edges
+// backwards. The vertex itself is only included if it is on a cycle.
+//
+// Since the graph only stores the out-edges of each vertex, this
+// requires a pass over all the edges of the graph.
+func (graph *Graph) Ancestors(vertex Vertex) []Vertex {
+	reverse := make(map[Vertex][]Vertex)
+	graph.DoEdges(func(source, target Vertex) error {
+		reverse[target] = append(reverse[target], source)
+		return nil
+	})
+	return reachFrom(vertex, func(vertex Vertex, fn func(Vertex)) {
+		for _, source := range reverse[vertex] {
+			fn(source)
+		}
+	})
+}
+
+// reachFrom will do a breadth-first search from 'start' using 'next'
+// to find the neighbours of each vertex, and return the vertices
+// found.
+func reachFrom(start Vertex, next func(Vertex, func(Vertex))) []Vertex {
+	var result []Vertex
+	seen := make(map[Vertex]bool)
+	queue := []Vertex{start}
+	for len(queue) > 0 {
+		vertex := queue[0]
+		queue = queue[1:]
+		next(vertex, func(neighbour Vertex) {
+			if !seen[neighbour] {
+				seen[neighbour] = true
+				result = append(result, neighbour)
+				queue = append(queue, neighbour)
+			}
+		})
+	}
+	return result
+}
+
+// Reachable will check if there is a path from 'source' to
+// 'target'. Every vertex in the graph is reachable from itself. The
+// search stops as soon as the target is found, but to answer many
+// queries, consider building a ReachabilityIndex.
+func (graph *Graph) Reachable(source, target Vertex) bool {
+	if !graph.HasVertex(source) || !graph.HasVertex(target) {
+		return false
+	}
+	found := source == target
+	walker := &fillableWalker{
+		onDiscover: func(vertex Vertex) error {
+			if vertex == target {
+				found = true
+				return StopWalk
+			}
+			return nil
+		},
+	}
+	if !found {
+		graph.BreadthFirstWalkFrom(walker, source)
+	}
+	return found
+}
+
+// interval is a closed interval of post-order numbers.
+type interval struct {
+	low, high int32
+}
+
+// ReachabilityIndex is a precomputed index that can answer
+// reachability queries for a graph in logarithmic time.
+//
+// The index is built on the condensation of the graph, that is, the
+// DAG formed by contracting each strongly connected component into a
+// single vertex. The components are numbered in post-order of a
+// depth-first spanning forest of the DAG, and each component is
+// labelled with a set of intervals covering the numbers of all
+// components reachable from it. For most graphs only a few intervals
+// are needed for each component, but in the worst case the index is
+// quadratic in the number of components.
+//
+// The index is a snapshot: later changes to the graph are not
+// reflected in the index.
+type ReachabilityIndex struct {
+	component map[Vertex]int32
+	post      []int32
+	intervals [][]interval
+}
+
+// strongComponents will compute the strongly connected components of
+// the snapshot using an iterative version of Tarjan's algorithm. The
+// components are numbered in the order they are completed, which is
+// a reverse topological order of the condensation: if there is an
+// edge from one component to another, the target component has a
+// lower number.
+func (compact *Compact) strongComponents() (component []int32, count int) {
+	n := compact.Order()
+	number := make([]int32, n)
+	low := make([]int32, n)
+	component = make([]int32, n)
+	for i := range component {
+		component[i] = -1
+	}
+	type frame struct {
+		vertex int32
+		next   int
+	}
+	var stack []int32
+	var calls []frame
+	time := int32(0)
+
+	for root := 0; root < n; root++ {
+		if number[root] != 0 {
+			continue
+		}
+		time++
+		number[root], low[root] = time, time
+		stack = append(stack, int32(root))
+		calls = append(calls, frame{int32(root), 0})
+		for len(calls) > 0 {
+			top := &calls[len(calls)-1]
+			edges := compact.OutEdges(int(top.vertex))
+			if top.next < len(edges) {
+				target := edges[top.next]
+				top.next++
+				if number[target] == 0 {
+					time++
+					number[target], low[target] = time, time
+					stack = append(stack, target)
+					calls = append(calls, frame{target, 0})
+				} else if component[target] < 0 && number[target] < low[top.vertex] {
+					low[top.vertex] = number[target]
+				}
+				continue
+			}
+
+			vertex := top.vertex
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].vertex
+				if low[vertex] < low[parent] {
+					low[parent] = low[vertex]
+				}
+			}
+			if low[vertex] == number[vertex] {
+				for {
+					member := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					component[member] = int32(count)
+					if member == vertex {
+						break
+					}
+				}
+				count++
+			}
+		}
+	}
+	return component, count
+}
+
+// ReachabilityIndex will build a reachability index for the graph.
+func (graph *Graph) ReachabilityIndex() *ReachabilityIndex {
+	compact := graph.Compact()
+	component, count := compact.strongComponents()
+
+	// Build the condensation, removing duplicate edges and edges
+	// inside components.
+	successors := make([][]int32, count)
+	mark := make([]int32, count)
+	for i := range mark {
+		mark[i] = -1
+	}
+	members := make([][]int32, count)
+	for v, c := range component {
+		members[c] = append(members[c], int32(v))
+	}
+	for c := range members {
+		for _, v := range members[c] {
+			for _, target := range compact.OutEdges(int(v)) {
+				d := component[target]
+				if d != int32(c) && mark[d] != int32(c) {
+					mark[d] = int32(c)
+					successors[c] = append(successors[c], d)
+				}
+			}
+		}
+	}
+
+	// Number the components in post-order of a depth-first
+	// spanning forest and record the lowest number in the subtree
+	// of each component.
+	post := make([]int32, count)
+	lowest := make([]int32, count)
+	visited := make([]bool, count)
+	type frame struct {
+		component int32
+		next      int
+	}
+	time := int32(0)
+	for root := count - 1; root >= 0; root-- {
+		if visited[root] {
+			continue
+		}
+		visited[root] = true
+		lowest[root] = time
+		calls := []frame{{int32(root), 0}}
+		for len(calls) > 0 {
+			top := &calls[len(calls)-1]
+			if top.next < len(successors[top.component]) {
+				next := successors[top.component][top.next]
+				top.next++
+				if !visited[next] {
+					visited[next] = true
+					lowest[next] = time
+					calls = append(calls, frame{next, 0})
+				}
+				continue
+			}
+			post[top.component] = time
+			time++
+			calls = calls[:len(calls)-1]
+		}
+	}
+
+	// Since the successors of a component have lower numbers, the
+	// intervals of the successors are computed before the
+	// intervals of the component.
+	intervals := make([][]interval, count)
+	for c := 0; c < count; c++ {
+		labels := []interval{{lowest[c], post[c]}}
+		for _, d := range successors[c] {
+			labels = append(labels, intervals[d]...)
+		}
+		intervals[c] = mergeIntervals(labels)
+	}
+
+	index := &ReachabilityIndex{
+		component: make(map[Vertex]int32, compact.Order()),
+		post:      post,
+		intervals: intervals,
+	}
+	for v, c := range component {
+		index.component[compact.Vertex(v)] = c
+	}
+	return index
+}
+
+// mergeIntervals will sort the intervals and merge overlapping and
+// adjacent intervals.
+func mergeIntervals(intervals []interval) []interval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].low < intervals[j].low
+	})
+	merged := intervals[:1]
+	for _, next := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if next.low <= last.high+1 {
+			if next.high > last.high {
+				last.high = next.high
+			}
+		} else {
+			merged = append(merged, next)
+		}
+	}
+	return append([]interval(nil), merged...)
+}
+
+// Reachable will check if there is a path from 'source' to 'target'
+// in the graph the index was built for. Every vertex in the graph is
+// reachable from itself.
+func (index *ReachabilityIndex) Reachable(source, target Vertex) bool {
+	cs, ok := index.component[source]
+	if !ok {
+		return false
+	}
+	ct, ok := index.component[target]
+	if !ok {
+		return false
+	}
+	if cs == ct {
+		return true
+	}
+	number := index.post[ct]
+	labels := index.intervals[cs]
+	i := sort.Search(len(labels), func(i int) bool {
+		return labels[i].high >= number
+	})
+	return i < len(labels) && labels[i].low <= number
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func sortedInts(vertices []Vertex) string {
+	ints := make([]int, len(vertices))
+	for i, v := range vertices {
+		ints[i] = v.(int)
+	}
+	sort.Ints(ints)
+	return fmt.Sprint(ints)
+}
+
+func TestAncestorsDescendants(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(3, 2)
+	graph.AddEdge(3, 4)
+	graph.AddEdge(5, 4)
+	graph.AddVertex(6)
+
+	tests := []struct {
+		vertex                 Vertex
+		descendants, ancestors string
+	}{
+		{1, "[2 3 4]", "[]"},
+		{2, "[2 3 4]", "[1 2 3]"},
+		{4, "[]", "[1 2 3 5]"},
+		{6, "[]", "[]"},
+		{7, "[]", "[]"},
+	}
+	for _, tt := range tests {
+		if got := sortedInts(graph.Descendants(tt.vertex)); got != tt.descendants {
+			t.Errorf("Descendants(%v) = %v, expected %v", tt.vertex, got, tt.descendants)
+		}
+		if got := sortedInts(graph.Ancestors(tt.vertex)); got != tt.ancestors {
+			t.Errorf("Ancestors(%v) = %v, expected %v", tt.vertex, got, tt.ancestors)
+		}
+	}
+
+	if order := graph.Descendants(1); fmt.Sprint(order) != "[2 3 4]" {
+		t.Errorf("Descendants not in breadth-first order: %v", order)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	random := rand.New(rand.NewSource(42))
+	for round := 0; round < 50; round++ {
+		order := 1 + random.Intn(30)
+		graph := New()
+		for v := 0; v < order; v++ {
+			graph.AddVertex(v)
+		}
+		for e := random.Intn(2 * order); e > 0; e-- {
+			graph.AddEdge(random.Intn(order), random.Intn(order))
+		}
+
+		index := graph.ReachabilityIndex()
+		for source := 0; source < order; source++ {
+			expected := make(map[Vertex]bool)
+			expected[source] = true
+			for _, v := range graph.Descendants(source) {
+				expected[v] = true
+			}
+			for target := 0; target < order; target++ {
+				if got := graph.Reachable(source, target); got != expected[target] {
+					t.Errorf("Reachable(%v, %v) = %v, expected %v",
+						source, target, got, expected[target])
+				}
+				if got := index.Reachable(source, target); got != expected[target] {
+					t.Errorf("Index reachable(%v, %v) = %v, expected %v",
+						source, target, got, expected[target])
+				}
+			}
+		}
+		if graph.Reachable(0, order) || index.Reachable(order, 0) {
+			t.Errorf("Missing vertex reported reachable")
+		}
+	}
+}
@@ -7,11 +7,10 @@ package directed
 
 import (
 	"container/list"
-)
+	"context"
 
-type sccInfo struct {
-	number, low int
-}
+	"github.com/mkindahl/gograph/internal/lowlink"
+)
 
 // GraphWalkFunc is a function called on subgraphs of a graph, for
 // example, when iterating over the strongly connected components of a
@@ -23,8 +22,8 @@ type GraphWalkFunc func(graph *Graph) error
 type sccWalker struct {
 	DefaultWalker
 	onComponent GraphWalkFunc
-	time        int
-	info        map[Vertex]*sccInfo
+	links       *lowlink.Table
+	onStack     map[Vertex]bool
 	stack       *list.List
 	graph       *Graph
 }
@@ -32,59 +31,53 @@ type sccWalker struct {
 // pushStack will push a vertex on the stack of unassigned vertices.
 func (walker *sccWalker) pushStack(vertex Vertex) {
 	walker.stack.PushBack(vertex)
+	walker.onStack[vertex] = true
 }
 
 // popStack will pop the topmost vertex from the stack of unassigned
 // vertices and return it with an indication if there are more
-// elements that need to be popped after this one.
-func (walker *sccWalker) popStack(low int) (more bool, vertex Vertex) {
+// elements that need to be popped after this one, that is, if the
+// vertex is not the root of the SCC.
+func (walker *sccWalker) popStack(root Vertex) (more bool, vertex Vertex) {
 	if elem := walker.stack.Back(); elem != nil {
-		vertex = elem.Value
-		walker.stack.Remove(elem)
-		top := walker.stack.Back()
-		if top != nil && walker.info[top.Value].low == low {
-			more = true
-		}
+		vertex = walker.stack.Remove(elem)
+		walker.onStack[vertex] = false
+		more = vertex != root
 	}
 	return
 }
 
-func (walker *sccWalker) OnDiscover(parent, vertex Vertex) error {
-	walker.time++
-	vinfo := &sccInfo{
-		number: walker.time,
-		low:    walker.time,
+// updateLow will lower the low-link of 'source' to the number of
+// 'target' if 'target' is still on the stack, that is, if it is part
+// of an SCC that is not yet complete.
+func (walker *sccWalker) updateLow(source, target Vertex) {
+	if walker.onStack[target] {
+		walker.links.Lower(source, walker.links.Number(target))
 	}
-	walker.info[vertex] = vinfo
+}
+
+func (walker *sccWalker) OnDiscover(parent, vertex Vertex) error {
+	walker.links.Discover(vertex)
 	walker.pushStack(vertex)
-	if parent != nil {
-		pinfo := walker.info[parent]
-		if vinfo.low < pinfo.low {
-			pinfo.low = vinfo.low
-		}
-	}
 	return nil
 }
 
 func (walker *sccWalker) OnBackEdge(source, target Vertex) error {
-	sinfo := walker.info[source]
-	tinfo := walker.info[target]
-	if tinfo.low < sinfo.low {
-		sinfo.low = tinfo.low
-	}
+	walker.updateLow(source, target)
+	return nil
+}
+
+func (walker *sccWalker) OnCrossEdge(source, target Vertex) error {
+	walker.updateLow(source, target)
 	return nil
 }
 
 func (walker *sccWalker) OnFinish(parent, vertex Vertex) error {
-	vinfo := walker.info[vertex]
-	pinfo := walker.info[parent]
-	if pinfo != nil && vinfo.low < pinfo.low {
-		pinfo.low = vinfo.low
-	}
+	walker.links.Finish(parent, vertex)
 
 	// Check if this is an SCC root vertex
-	if vinfo.number == vinfo.low {
-		more, svertex := walker.popStack(vinfo.number)
+	if walker.links.IsRoot(vertex) {
+		more, svertex := walker.popStack(vertex)
 		// Check if there is at least one more vertex to pop,
 		// if there is, we have an SCC of size > 1
 		if more {
@@ -93,7 +86,7 @@ func (walker *sccWalker) OnFinish(parent, vertex Vertex) error {
 			scc := New()
 			scc.AddVertex(svertex)
 			for more {
-				more, svertex = walker.popStack(vinfo.number)
+				more, svertex = walker.popStack(vertex)
 				scc.AddVertex(svertex)
 			}
 			// Function that check if the target vertex is
@@ -131,12 +124,23 @@ func (walker *sccWalker) OnFinish(parent, vertex Vertex) error {
 // The intention is normally to use the algorithm to find cycles in
 // the graph, and this behaviour defeats the purpose, so we only
 // consider SCCs of size larger than 1.
-func (graph *Graph) DoCycles(onComponent GraphWalkFunc) {
+//
+// If onComponent returns an error, the walk is aborted and the error
+// returned. Returning StopWalk will end the walk without an error.
+func (graph *Graph) DoCycles(onComponent GraphWalkFunc) error {
+	return graph.DoCyclesContext(context.Background(), onComponent)
+}
+
+// DoCyclesContext will call the onComponent function for each SCC of
+// size larger than 1 in the same way as DoCycles, but the walk is
+// aborted with the error of the context if the context is cancelled.
+func (graph *Graph) DoCyclesContext(ctx context.Context, onComponent GraphWalkFunc) error {
 	walker := &sccWalker{
 		graph:       graph,
-		info:        make(map[Vertex]*sccInfo),
+		links:       lowlink.New(),
+		onStack:     make(map[Vertex]bool),
 		onComponent: onComponent,
 		stack:       list.New(),
 	}
-	graph.DepthFirstWalk(walker)
+	return graph.DepthFirstWalkContext(ctx, walker)
 }
@@ -7,7 +7,10 @@
 // directed graphs.
 package directed
 
-import "container/list"
+import (
+	"container/list"
+	"sort"
+)
 
 //import "fmt"
 
@@ -18,10 +21,42 @@ type Vertex interface{}
 
 type adjacencyList map[Vertex]*list.List
 
+// Edge is a convenience declaration for an edge of the graph, given
+// as the pair of source and target vertices. It can be used as key in
+// a map.
+type Edge struct {
+	Source, Target Vertex
+}
+
+// WeightFunc is a function returning the weight of the edge from
+// source to target. It is used by the algorithms that work on
+// weighted graphs.
+type WeightFunc func(source, target Vertex) float64
+
+// Weights are the weights of the edges of a graph.
+type Weights map[Edge]float64
+
+// Weight will return the weight of the edge from 'source' to
+// 'target', or 1 if the edge has no weight, so that edges without a
+// weight count as single steps. The method can be used as a
+// WeightFunc.
+func (weights Weights) Weight(source, target Vertex) float64 {
+	if weight, ok := weights[Edge{source, target}]; ok {
+		return weight
+	}
+	return 1
+}
+
 // Graph is the respresentation of a directed graph. It contain all
 // the edges and vertices of the graph.
+//
+// The graph also keeps track of the order in which the vertices were
+// added, so that iterating over the vertices and edges, and walking
+// the graph, is done in a deterministic order.
 type Graph struct {
 	edges                  adjacencyList
+	order                  *list.List
+	position               map[Vertex]*list.Element
 	edgeCount, vertexCount int
 }
 
@@ -44,7 +79,11 @@ func find(lst *list.List, value Vertex) (bool, *list.Element) {
 
 // New will create a new, empty, directed graph.
 func New() *Graph {
-	return &Graph{edges: make(adjacencyList)}
+	return &Graph{
+		edges:    make(adjacencyList),
+		order:    list.New(),
+		position: make(map[Vertex]*list.Element),
+	}
 }
 
 // AddEdge add an edge to the graph. The source and target vertices
@@ -89,6 +128,7 @@ func (graph *Graph) RemoveEdge(source, target Vertex) bool {
 func (graph *Graph) AddVertex(vertex Vertex) bool {
 	if graph.edges[vertex] == nil {
 		graph.edges[vertex] = list.New()
+		graph.position[vertex] = graph.order.PushBack(vertex)
 		graph.vertexCount++
 		//		fmt.Printf("Adding %d vertex, giving %d\n", 1, graph.vertexCount)
 		return true
@@ -109,6 +149,8 @@ func (graph *Graph) RemoveVertex(vertex Vertex) bool {
 		// fmt.Printf("Removing %d edges, giving %d\n",
 		//            graph.edges[vertex].Len(), graph.edgeCount)
 		delete(graph.edges, vertex)
+		graph.order.Remove(graph.position[vertex])
+		delete(graph.position, vertex)
 
 		// Iterate over all the other lists to remove all
 		// in-edges.
@@ -159,33 +201,49 @@ func (graph *Graph) Size() int {
 type VertexWalkFunc func(vertex Vertex) error
 
 // DoVertices iterate over all the vertices of the graph calling
-// 'walkFn' with each vertex. If the walk function returns an error,
-// iteration will be aborted and the error returned to the caller.
+// 'walkFn' with each vertex, in the order the vertices were added to
+// the graph. If the walk function returns an error, iteration will be
+// aborted and the error returned to the caller.
 func (graph *Graph) DoVertices(walkFn VertexWalkFunc) error {
-	for vertex := range graph.edges {
-		if err := walkFn(vertex); err != nil {
+	for elem := graph.order.Front(); elem != nil; {
+		// Fetch the next element first, in case the walk
+		// function removes the vertex.
+		next := elem.Next()
+		if err := walkFn(elem.Value); err != nil {
 			return err
 		}
+		elem = next
 	}
 	return nil
 }
 
+// SortedVertices will return a slice with the vertices of the graph
+// sorted using 'less'. The slice can be used to walk the graph with
+// the roots in a well-defined order.
+func (graph *Graph) SortedVertices(less func(a, b Vertex) bool) []Vertex {
+	vertices := make([]Vertex, 0, graph.vertexCount)
+	for elem := graph.order.Front(); elem != nil; elem = elem.Next() {
+		vertices = append(vertices, elem.Value)
+	}
+	sort.SliceStable(vertices, func(i, j int) bool {
+		return less(vertices[i], vertices[j])
+	})
+	return vertices
+}
+
 // EdgeWalkFunc is a function called when walking edges of a graph.
 type EdgeWalkFunc func(source, target Vertex) error
 
 // DoEdges will iterate over all the edges of the graph calling
-// 'walkFn' with the source and target vertex of the edge. If the walk
+// 'walkFn' with the source and target vertex of the edge. The edges
+// are processed in the order of their source vertices, and the edges
+// of each source vertex in the order they were added. If the walk
 // function return an error, iteration will be aborted and the error
 // returned.
 func (graph *Graph) DoEdges(walkFn EdgeWalkFunc) error {
-	for vertex, edges := range graph.edges {
-		for elem := edges.Front(); elem != nil; elem = elem.Next() {
-			if err := walkFn(vertex, elem.Value); err != nil {
-				return err
-			}
-		}
-	}
-	return nil
+	return graph.DoVertices(func(vertex Vertex) error {
+		return graph.DoOutEdges(vertex, walkFn)
+	})
 }
 
 // DoOutEdges iterate over the out-edges of a vertex, calling 'walkFn'
@@ -7,6 +7,7 @@ package directed
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -214,3 +215,42 @@ func TestVertexEdgeCount(t *testing.T) {
 	graph.RemoveVertex(3)
 	checkGraphCount(t, graph, 3, 0)
 }
+
+func TestVertexOrder(t *testing.T) {
+	graph := New()
+	for _, vertex := range []int{5, 3, 8, 1, 9} {
+		graph.AddVertex(vertex)
+	}
+	graph.AddEdge(2, 5)
+	graph.RemoveVertex(8)
+	graph.AddVertex(8)
+
+	var order []Vertex
+	graph.DoVertices(func(vertex Vertex) error {
+		order = append(order, vertex)
+		return nil
+	})
+	if fmt.Sprint(order) != "[5 3 1 9 2 8]" {
+		t.Errorf("Vertices not in insertion order: %v", order)
+	}
+
+	sorted := graph.SortedVertices(func(a, b Vertex) bool {
+		return a.(int) < b.(int)
+	})
+	if fmt.Sprint(sorted) != "[1 2 3 5 8 9]" {
+		t.Errorf("Vertices not sorted: %v", sorted)
+	}
+}
+
+func TestWeights(t *testing.T) {
+	weights := Weights{{"a", "b"}: 2.5, {"b", "c"}: 0}
+	if w := weights.Weight("a", "b"); w != 2.5 {
+		t.Errorf("Expected weight 2.5, got %v", w)
+	}
+	if w := weights.Weight("b", "c"); w != 0 {
+		t.Errorf("Expected weight 0, got %v", w)
+	}
+	if w := weights.Weight("c", "a"); w != 1 {
+		t.Errorf("Expected default weight 1, got %v", w)
+	}
+}
@@ -5,7 +5,10 @@
 
 package directed
 
-import "container/list"
+import (
+	"container/list"
+	"context"
+)
 
 type topologicalWalker struct {
 	DefaultWalker
@@ -20,12 +23,25 @@ func (walker *topologicalWalker) OnFinish(parent, vertex Vertex) error {
 // DoTopological will process the graph in topological order and call
 // onDiscover with each step.
 func (graph *Graph) DoTopological(onDiscover VertexWalkFunc) error {
+	return graph.DoTopologicalContext(context.Background(), onDiscover)
+}
+
+// DoTopologicalContext will process the graph in topological order in
+// the same way as DoTopological, but processing is aborted with the
+// error of the context if the context is cancelled.
+func (graph *Graph) DoTopologicalContext(ctx context.Context, onDiscover VertexWalkFunc) error {
 	walker := &topologicalWalker{
 		vertices: list.New(),
 	}
-	graph.DepthFirstWalk(walker)
+	if err := graph.DepthFirstWalkContext(ctx, walker); err != nil {
+		return err
+	}
 	// Process elements in reverse order of finishing time
+	cancel := newCanceller(ctx)
 	for elem := walker.vertices.Front(); elem != nil; elem = elem.Next() {
+		if err := cancel.check(); err != nil {
+			return err
+		}
 		if err := onDiscover(elem.Value); err != nil {
 			return err
 		}
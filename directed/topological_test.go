@@ -5,7 +5,10 @@
 
 package directed
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestTopologicalWalk(t *testing.T) {
 	graph := New()
@@ -37,3 +40,20 @@ func TestTopologicalWalk(t *testing.T) {
 		t.Errorf("Not in topological order %v\n", when)
 	}
 }
+
+func TestTopologicalDeterministic(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(1, 3)
+	graph.AddEdge(4, 3)
+	graph.AddEdge(3, 5)
+	graph.AddEdge(2, 5)
+	var order []Vertex
+	graph.DoTopological(func(vertex Vertex) error {
+		order = append(order, vertex)
+		return nil
+	})
+	if fmt.Sprint(order) != "[4 1 3 2 5]" {
+		t.Errorf("Wrong topological order: %v", order)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import "github.com/mkindahl/gograph/undirected"
+
+// WeakConnectivity will return a connectivity structure for the
+// weakly connected components of the graph, that is, the connected
+// components of the graph when the direction of the edges is
+// ignored. The structure can be used to query the components and to
+// track how they change as more edges are added.
+func (graph *Graph) WeakConnectivity() *undirected.Connectivity {
+	conn := undirected.NewConnectivity()
+	graph.DoVertices(func(vertex Vertex) error {
+		conn.AddVertex(vertex)
+		return nil
+	})
+	graph.DoEdges(func(source, target Vertex) error {
+		conn.AddEdge(source, target)
+		return nil
+	})
+	return conn
+}
+
+// DoWeakComponents will call the onComponent function for each weakly
+// connected component of the graph, including components consisting
+// of a single vertex. If the function returns an error, iteration
+// will be aborted and the error returned.
+func (graph *Graph) DoWeakComponents(onComponent GraphWalkFunc) error {
+	conn := graph.WeakConnectivity()
+	return conn.DoComponents(func(vertices []undirected.Vertex) error {
+		component := New()
+		for _, vertex := range vertices {
+			component.AddVertex(vertex)
+		}
+		// Since the component is weakly connected, all the
+		// out-edges of the vertices are inside the component.
+		for _, vertex := range vertices {
+			graph.DoOutEdges(vertex, func(source, target Vertex) error {
+				component.AddEdge(source, target)
+				return nil
+			})
+		}
+		return onComponent(component)
+	})
+}
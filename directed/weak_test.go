@@ -0,0 +1,40 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package directed
+
+import "testing"
+
+func TestWeakComponents(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(3, 2)
+	graph.AddEdge(4, 5)
+	graph.AddVertex(6)
+
+	conn := graph.WeakConnectivity()
+	if conn.Count() != 3 {
+		t.Errorf("Wrong number of components (was %d, expected %d)", conn.Count(), 3)
+	}
+	if !conn.SameComponent(1, 3) {
+		t.Errorf("%v and %v not in same component", 1, 3)
+	}
+	conn.AddEdge(5, 6)
+	if conn.Count() != 2 || !conn.SameComponent(4, 6) {
+		t.Errorf("Edge not added incrementally")
+	}
+
+	count := 0
+	graph.DoWeakComponents(func(component *Graph) error {
+		count++
+		if component.HasVertex(1) {
+			checkGraphCount(t, component, 3, 2)
+		}
+		return nil
+	})
+	if count != 3 {
+		t.Errorf("Wrong number of components (was %d, expected %d)", count, 3)
+	}
+}
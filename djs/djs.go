@@ -38,10 +38,24 @@ func (ds *DisjointSet) MakeSet(value interface{}) {
 // the same set. If they are different, they are in different sets.
 func (ds *DisjointSet) Find(value interface{}) *Node {
 	node := ds.nodes[value]
-	for node != node.parent {
-		node = node.parent
+	root := node
+	for root != root.parent {
+		root = root.parent
 	}
-	return node.parent
+	// Compress the path so that all nodes on it point directly at
+	// the representative.
+	for node != root {
+		next := node.parent
+		node.parent = root
+		node = next
+	}
+	return root
+}
+
+// Check if 'value' is a member of any set of the disjoint-set
+// structure.
+func (ds *DisjointSet) Contains(value interface{}) bool {
+	return ds.nodes[value] != nil
 }
 
 // Merge the two sets that 'x' and 'y' are members of.
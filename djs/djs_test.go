@@ -86,3 +86,16 @@ func TestDisjointSet(t *testing.T) {
 		}
 	}
 }
+
+func TestContains(t *testing.T) {
+	ds := New()
+	ds.MakeSet(1)
+	ds.MakeSet(2)
+	ds.Union(1, 2)
+	if !ds.Contains(1) || !ds.Contains(2) {
+		t.Errorf("Member missing")
+	}
+	if ds.Contains(3) {
+		t.Errorf("Extreneous member %v", 3)
+	}
+}
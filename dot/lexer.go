@@ -0,0 +1,261 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package dot
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SyntaxError is returned when the input is not valid DOT. It gives
+// the line and column, both starting at 1, where the error was found.
+type SyntaxError struct {
+	Line, Column int
+	Msg          string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("dot: line %d, column %d: %s", err.Line, err.Column, err.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenID
+	tokenQuoted
+	tokenHTML
+	tokenEdgeOp
+	tokenPunct
+)
+
+// token is a single token of the input together with the position
+// where it started.
+type token struct {
+	kind         tokenKind
+	text         string
+	line, column int
+}
+
+func (tok token) String() string {
+	switch tok.kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenQuoted:
+		return Quote(tok.text)
+	case tokenHTML:
+		return "HTML string"
+	}
+	return fmt.Sprintf("%q", tok.text)
+}
+
+// keyword will check if the token is the given keyword. Keywords are
+// not case sensitive, and quoted strings are never keywords.
+func (tok token) keyword(word string) bool {
+	return tok.kind == tokenID && strings.EqualFold(tok.text, word)
+}
+
+// isID will check if the token can be used as an ID.
+func (tok token) isID() bool {
+	switch tok.kind {
+	case tokenQuoted, tokenHTML:
+		return true
+	case tokenID:
+		for _, word := range []string{"node", "edge", "graph", "digraph", "subgraph", "strict"} {
+			if tok.keyword(word) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// lexer splits the input into tokens while keeping track of the line
+// and column.
+type lexer struct {
+	input        string
+	pos          int
+	line, column int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: input, line: 1, column: 1}
+}
+
+func (lex *lexer) errorf(line, column int, format string, args ...interface{}) error {
+	return &SyntaxError{line, column, fmt.Sprintf(format, args...)}
+}
+
+// peek will return the next rune of the input without consuming it,
+// or -1 at the end of the input.
+func (lex *lexer) peek() rune {
+	if lex.pos >= len(lex.input) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(lex.input[lex.pos:])
+	return r
+}
+
+// next will consume and return the next rune of the input.
+func (lex *lexer) next() rune {
+	if lex.pos >= len(lex.input) {
+		return -1
+	}
+	r, size := utf8.DecodeRuneInString(lex.input[lex.pos:])
+	lex.pos += size
+	if r == '\n' {
+		lex.line++
+		lex.column = 1
+	} else {
+		lex.column++
+	}
+	return r
+}
+
+// skipSpace will skip white space and comments. Lines starting with
+// '#' are treated as comments, since they are output from the C
+// preprocessor.
+func (lex *lexer) skipSpace() error {
+	for {
+		r := lex.peek()
+		switch {
+		case r == '#' && lex.column == 1:
+			for r != '\n' && r != -1 {
+				lex.next()
+				r = lex.peek()
+			}
+		case unicode.IsSpace(r):
+			lex.next()
+		case strings.HasPrefix(lex.input[lex.pos:], "//"):
+			for r != '\n' && r != -1 {
+				lex.next()
+				r = lex.peek()
+			}
+		case strings.HasPrefix(lex.input[lex.pos:], "/*"):
+			line, column := lex.line, lex.column
+			end := strings.Index(lex.input[lex.pos+2:], "*/")
+			if end < 0 {
+				return lex.errorf(line, column, "unterminated comment")
+			}
+			for stop := lex.pos + 2 + end + 2; lex.pos < stop; {
+				lex.next()
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// isIDRune will check if 'r' can be part of an unquoted identifier.
+func isIDRune(r rune) bool {
+	return r == '_' || r >= 0x80 || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+}
+
+// token will return the next token of the input.
+func (lex *lexer) token() (token, error) {
+	if err := lex.skipSpace(); err != nil {
+		return token{}, err
+	}
+	tok := token{line: lex.line, column: lex.column}
+	start := lex.pos
+	r := lex.next()
+	switch {
+	case r == -1:
+		tok.kind = tokenEOF
+	case r == '"':
+		text, err := lex.quoted()
+		if err != nil {
+			return tok, lex.errorf(tok.line, tok.column, "%v", err)
+		}
+		tok.kind, tok.text = tokenQuoted, text
+	case r == '<':
+		text, err := lex.html()
+		if err != nil {
+			return tok, lex.errorf(tok.line, tok.column, "%v", err)
+		}
+		tok.kind, tok.text = tokenHTML, text
+	case r == '-' && (lex.peek() == '>' || lex.peek() == '-'):
+		lex.next()
+		tok.kind, tok.text = tokenEdgeOp, lex.input[start:lex.pos]
+	case r == '-' || r == '.' || '0' <= r && r <= '9':
+		for r := lex.peek(); r == '.' || '0' <= r && r <= '9'; r = lex.peek() {
+			lex.next()
+		}
+		tok.kind, tok.text = tokenID, lex.input[start:lex.pos]
+		if tok.text == "-" || tok.text == "." || tok.text == "-." ||
+			strings.Count(tok.text, ".") > 1 {
+			return tok, lex.errorf(tok.line, tok.column, "invalid number %q", tok.text)
+		}
+		if isIDRune(lex.peek()) {
+			return tok, lex.errorf(lex.line, lex.column,
+				"unexpected %q after number %q", lex.peek(), tok.text)
+		}
+	case isIDRune(r):
+		for isIDRune(lex.peek()) {
+			lex.next()
+		}
+		tok.kind, tok.text = tokenID, lex.input[start:lex.pos]
+	case strings.ContainsRune("{}[];,=:+", r):
+		tok.kind, tok.text = tokenPunct, string(r)
+	default:
+		return tok, lex.errorf(tok.line, tok.column, "unexpected character %q", r)
+	}
+	return tok, nil
+}
+
+// quoted will read the rest of a quoted string. The escape sequences
+// written by Quote are translated back, a backslash followed by a
+// newline is removed, and other backslashes are kept, since they are
+// interpreted by Graphviz, for example "\l" in labels.
+func (lex *lexer) quoted() (string, error) {
+	var builder strings.Builder
+	for {
+		switch r := lex.next(); r {
+		case -1:
+			return "", fmt.Errorf("unterminated string")
+		case '"':
+			return builder.String(), nil
+		case '\\':
+			switch escaped := lex.next(); escaped {
+			case -1:
+				return "", fmt.Errorf("unterminated string")
+			case '"', '\\':
+				builder.WriteRune(escaped)
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case '\n':
+			default:
+				builder.WriteRune('\\')
+				builder.WriteRune(escaped)
+			}
+		default:
+			builder.WriteRune(r)
+		}
+	}
+}
+
+// html will read the rest of an HTML string, which is delimited by
+// matching angle brackets. The string is returned with the enclosing
+// angle brackets.
+func (lex *lexer) html() (string, error) {
+	start := lex.pos - 1
+	for depth := 1; depth > 0; {
+		switch lex.next() {
+		case -1:
+			return "", fmt.Errorf("unterminated HTML string")
+		case '<':
+			depth++
+		case '>':
+			depth--
+		}
+	}
+	return lex.input[start:lex.pos], nil
+}
@@ -0,0 +1,538 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package dot
+
+import (
+	"errors"
+	"io"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Graph is a graph read from DOT input together with the attributes
+// of the graph, its vertices, and its edges. The vertices are the IDs
+// used in the input, as strings.
+type Graph struct {
+	*directed.Graph
+
+	// Name of the graph, or an empty string if it is anonymous.
+	Name string
+
+	// Strict is true if the graph was declared strict.
+	Strict bool
+
+	// Directed is false if the input was an undirected graph, in
+	// which case each edge was added in both directions.
+	Directed bool
+
+	// Attributes of the graph as a whole.
+	Attributes Attributes
+
+	// VertexAttributes are the attributes of each vertex,
+	// including any default attributes that were in effect when
+	// the vertex was first used.
+	VertexAttributes map[directed.Vertex]Attributes
+
+	// EdgeAttributes are the attributes of each edge, including
+	// any default attributes in effect for the edge statement.
+	EdgeAttributes map[directed.Edge]Attributes
+
+	// Subgraphs are the subgraphs in the order they first appear
+	// in the input. Subgraphs with the same name are merged.
+	Subgraphs []*Subgraph
+}
+
+// ErrAttributes is returned when trying to save or load the graph
+// using the JSON Graph Format or a binary snapshot, which cannot hold
+// the name and attributes. The methods of the embedded graph can be used
+// to save or load only the vertices and edges.
+var ErrAttributes = errors.New("dot: graph has attributes that cannot be saved, use Writer")
+
+// MarshalJSON will return ErrAttributes.
+func (graph *Graph) MarshalJSON() ([]byte, error) {
+	return nil, ErrAttributes
+}
+
+// UnmarshalJSON will return ErrAttributes.
+func (graph *Graph) UnmarshalJSON(data []byte) error {
+	return ErrAttributes
+}
+
+// WriteTo will return ErrAttributes.
+func (graph *Graph) WriteTo(out io.Writer) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// WriteWeightedTo will return ErrAttributes.
+func (graph *Graph) WriteWeightedTo(out io.Writer, weight directed.WeightFunc) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// ReadFrom will return ErrAttributes.
+func (graph *Graph) ReadFrom(in io.Reader) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// ReadWeightedFrom will return ErrAttributes.
+func (graph *Graph) ReadWeightedFrom(in io.Reader) (directed.Weights, int64, error) {
+	return nil, 0, ErrAttributes
+}
+
+// Subgraph is a subgraph read from DOT input.
+type Subgraph struct {
+	// Name of the subgraph, or an empty string if it is
+	// anonymous.
+	Name string
+
+	// Attributes of the subgraph.
+	Attributes Attributes
+
+	// Vertices of the subgraph, including the vertices of any
+	// nested subgraphs, in the order they first appear.
+	Vertices []directed.Vertex
+}
+
+// Writer will return a writer that writes the graph with the
+// attributes that were read. Subgraphs are not written.
+func (graph *Graph) Writer() *Writer {
+	return &Writer{
+		Name:       graph.Name,
+		Attributes: graph.Attributes,
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			return graph.VertexAttributes[vertex]
+		},
+		EdgeAttributes: func(source, target directed.Vertex) Attributes {
+			return graph.EdgeAttributes[directed.Edge{Source: source, Target: target}]
+		},
+	}
+}
+
+// scope keeps track of the default attributes and the vertices of a
+// graph or subgraph while it is being parsed.
+type scope struct {
+	parent     *scope
+	subgraph   *Subgraph
+	attributes Attributes
+	node, edge Attributes
+	vertices   []directed.Vertex
+	seen       map[directed.Vertex]bool
+}
+
+func newScope(parent *scope, attributes Attributes) *scope {
+	child := &scope{
+		parent:     parent,
+		attributes: attributes,
+		node:       Attributes{},
+		edge:       Attributes{},
+		seen:       make(map[directed.Vertex]bool),
+	}
+	if parent != nil {
+		child.node.merge(parent.node)
+		child.edge.merge(parent.edge)
+	}
+	return child
+}
+
+// merge will add all the attributes of 'other', replacing any
+// attributes with the same name.
+func (attrs Attributes) merge(other Attributes) {
+	for name, value := range other {
+		attrs[name] = value
+	}
+}
+
+// parser is a recursive descent parser for the DOT language.
+type parser struct {
+	lex       *lexer
+	tok       token
+	graph     *Graph
+	subgraphs map[string]*Subgraph
+	members   map[*Subgraph]map[directed.Vertex]bool
+}
+
+// Read will read a graph in DOT format from 'in'. Syntax errors are
+// returned as a *SyntaxError giving the position of the error.
+//
+// Edge chains, subgraphs as edge endpoints, default attributes, and
+// comments are supported. Ports are accepted but ignored, and HTML
+// strings are kept as strings with the enclosing angle brackets.
+func Read(in io.Reader) (*Graph, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		lex: newLexer(string(data)),
+		graph: &Graph{
+			Graph:            directed.New(),
+			Attributes:       Attributes{},
+			VertexAttributes: make(map[directed.Vertex]Attributes),
+			EdgeAttributes:   make(map[directed.Edge]Attributes),
+		},
+		subgraphs: make(map[string]*Subgraph),
+		members:   make(map[*Subgraph]map[directed.Vertex]bool),
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if err := p.parseGraph(); err != nil {
+		return nil, err
+	}
+	return p.graph, nil
+}
+
+// advance will read the next token.
+func (p *parser) advance() error {
+	tok, err := p.lex.token()
+	p.tok = tok
+	return err
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.lex.errorf(p.tok.line, p.tok.column, format, args...)
+}
+
+// punct will check if the current token is the given punctuation.
+func (p *parser) punct(text string) bool {
+	return p.tok.kind == tokenPunct && p.tok.text == text
+}
+
+// expect will check that the current token is the given punctuation
+// and skip it.
+func (p *parser) expect(text string) error {
+	if !p.punct(text) {
+		return p.errorf("expected %q, found %v", text, p.tok)
+	}
+	return p.advance()
+}
+
+// parseGraph will parse a complete graph.
+func (p *parser) parseGraph() error {
+	if p.tok.keyword("strict") {
+		p.graph.Strict = true
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case p.tok.keyword("digraph"):
+		p.graph.Directed = true
+	case p.tok.keyword("graph"):
+		p.graph.Directed = false
+	default:
+		return p.errorf("expected \"graph\" or \"digraph\", found %v", p.tok)
+	}
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if p.tok.isID() {
+		name, err := p.parseID()
+		if err != nil {
+			return err
+		}
+		p.graph.Name = name
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	if err := p.parseStatements(newScope(nil, p.graph.Attributes)); err != nil {
+		return err
+	}
+	if err := p.expect("}"); err != nil {
+		return err
+	}
+	if p.tok.kind != tokenEOF {
+		return p.errorf("unexpected %v after graph", p.tok)
+	}
+	return nil
+}
+
+// parseID will parse an ID, including concatenation of quoted
+// strings using '+'.
+func (p *parser) parseID() (string, error) {
+	if !p.tok.isID() {
+		return "", p.errorf("expected ID, found %v", p.tok)
+	}
+	id, quoted := p.tok.text, p.tok.kind == tokenQuoted
+	if err := p.advance(); err != nil {
+		return "", err
+	}
+	for quoted && p.punct("+") {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		if p.tok.kind != tokenQuoted {
+			return "", p.errorf("expected quoted string after '+', found %v", p.tok)
+		}
+		id += p.tok.text
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+// parseStatements will parse a statement list up to, but not
+// including, the closing brace.
+func (p *parser) parseStatements(sc *scope) error {
+	for !p.punct("}") && p.tok.kind != tokenEOF {
+		if err := p.parseStatement(sc); err != nil {
+			return err
+		}
+		if p.punct(";") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseStatement will parse a single statement.
+func (p *parser) parseStatement(sc *scope) error {
+	switch {
+	case p.tok.keyword("graph"), p.tok.keyword("node"), p.tok.keyword("edge"):
+		kind := p.tok
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if !p.punct("[") {
+			return p.errorf("expected attribute list after %v", kind)
+		}
+		attrs, err := p.parseAttributes()
+		if err != nil {
+			return err
+		}
+		switch {
+		case kind.keyword("graph"):
+			sc.attributes.merge(attrs)
+		case kind.keyword("node"):
+			sc.node.merge(attrs)
+		default:
+			sc.edge.merge(attrs)
+		}
+		return nil
+
+	case p.tok.keyword("subgraph"), p.punct("{"):
+		vertices, err := p.parseSubgraph(sc)
+		if err != nil {
+			return err
+		}
+		if p.tok.kind == tokenEdgeOp {
+			return p.parseEdges(sc, vertices)
+		}
+		return nil
+
+	case p.tok.isID():
+		id, err := p.parseID()
+		if err != nil {
+			return err
+		}
+		if p.punct("=") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			value, err := p.parseID()
+			if err != nil {
+				return err
+			}
+			sc.attributes[id] = value
+			return nil
+		}
+		if err := p.parsePort(); err != nil {
+			return err
+		}
+		p.addVertex(sc, id)
+		if p.tok.kind == tokenEdgeOp {
+			return p.parseEdges(sc, []directed.Vertex{id})
+		}
+		if p.punct("[") {
+			attrs, err := p.parseAttributes()
+			if err != nil {
+				return err
+			}
+			p.graph.VertexAttributes[id].merge(attrs)
+		}
+		return nil
+	}
+	return p.errorf("unexpected %v", p.tok)
+}
+
+// parsePort will parse and ignore an optional port and compass point
+// following a vertex ID.
+func (p *parser) parsePort() error {
+	for i := 0; i < 2 && p.punct(":"); i++ {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if _, err := p.parseID(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseAttributes will parse one or more attribute lists.
+func (p *parser) parseAttributes() (Attributes, error) {
+	attrs := Attributes{}
+	for p.punct("[") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for !p.punct("]") {
+			name, err := p.parseID()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			value, err := p.parseID()
+			if err != nil {
+				return nil, err
+			}
+			attrs[name] = value
+			if p.punct(",") || p.punct(";") {
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return attrs, nil
+}
+
+// parseSubgraph will parse a subgraph and return its vertices.
+func (p *parser) parseSubgraph(parent *scope) ([]directed.Vertex, error) {
+	name := ""
+	if p.tok.keyword("subgraph") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.isID() {
+			id, err := p.parseID()
+			if err != nil {
+				return nil, err
+			}
+			name = id
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	subgraph := p.subgraphs[name]
+	if subgraph == nil || name == "" {
+		subgraph = &Subgraph{Name: name, Attributes: Attributes{}}
+		p.graph.Subgraphs = append(p.graph.Subgraphs, subgraph)
+		p.members[subgraph] = make(map[directed.Vertex]bool)
+		if name != "" {
+			p.subgraphs[name] = subgraph
+		}
+	}
+	sc := newScope(parent, subgraph.Attributes)
+	sc.subgraph = subgraph
+	if err := p.parseStatements(sc); err != nil {
+		return nil, err
+	}
+	if err := p.expect("}"); err != nil {
+		return nil, err
+	}
+	return sc.vertices, nil
+}
+
+// parseEdges will parse the rest of an edge statement, starting with
+// the first edge operator, and add the edges between each pair of
+// consecutive endpoints. Each endpoint is either a vertex or all the
+// vertices of a subgraph.
+func (p *parser) parseEdges(sc *scope, first []directed.Vertex) error {
+	endpoints := [][]directed.Vertex{first}
+	for p.tok.kind == tokenEdgeOp {
+		if p.graph.Directed && p.tok.text != "->" {
+			return p.errorf("undirected edge in directed graph")
+		}
+		if !p.graph.Directed && p.tok.text != "--" {
+			return p.errorf("directed edge in undirected graph")
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if p.tok.keyword("subgraph") || p.punct("{") {
+			vertices, err := p.parseSubgraph(sc)
+			if err != nil {
+				return err
+			}
+			endpoints = append(endpoints, vertices)
+			continue
+		}
+		id, err := p.parseID()
+		if err != nil {
+			return err
+		}
+		if err := p.parsePort(); err != nil {
+			return err
+		}
+		p.addVertex(sc, id)
+		endpoints = append(endpoints, []directed.Vertex{id})
+	}
+
+	attrs := Attributes{}
+	attrs.merge(sc.edge)
+	if p.punct("[") {
+		explicit, err := p.parseAttributes()
+		if err != nil {
+			return err
+		}
+		attrs.merge(explicit)
+	}
+
+	for i := 1; i < len(endpoints); i++ {
+		for _, source := range endpoints[i-1] {
+			for _, target := range endpoints[i] {
+				p.addEdge(source, target, attrs)
+				if !p.graph.Directed {
+					p.addEdge(target, source, attrs)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addVertex will add a vertex to the graph, if it is new, and to the
+// scope and all enclosing scopes.
+func (p *parser) addVertex(sc *scope, vertex directed.Vertex) {
+	if !p.graph.HasVertex(vertex) {
+		p.graph.AddVertex(vertex)
+		attrs := Attributes{}
+		attrs.merge(sc.node)
+		p.graph.VertexAttributes[vertex] = attrs
+	}
+	for ; sc != nil; sc = sc.parent {
+		if !sc.seen[vertex] {
+			sc.seen[vertex] = true
+			sc.vertices = append(sc.vertices, vertex)
+		}
+		if sc.subgraph != nil && !p.members[sc.subgraph][vertex] {
+			p.members[sc.subgraph][vertex] = true
+			sc.subgraph.Vertices = append(sc.subgraph.Vertices, vertex)
+		}
+	}
+}
+
+// addEdge will add an edge to the graph and merge the attributes with
+// the attributes of the edge.
+func (p *parser) addEdge(source, target directed.Vertex, attrs Attributes) {
+	p.graph.AddEdge(source, target)
+	edge := directed.Edge{Source: source, Target: target}
+	if p.graph.EdgeAttributes[edge] == nil {
+		p.graph.EdgeAttributes[edge] = Attributes{}
+	}
+	p.graph.EdgeAttributes[edge].merge(attrs)
+}
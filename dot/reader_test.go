@@ -0,0 +1,198 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package dot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func edgeList(graph *directed.Graph) string {
+	var edges []string
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		edges = append(edges, fmt.Sprintf("%v->%v", source, target))
+		return nil
+	})
+	return strings.Join(edges, " ")
+}
+
+func TestRead(t *testing.T) {
+	input := `
+# preprocessor line
+/* A digraph with
+   most features. */
+strict digraph "deps" {
+	rankdir = LR;             // graph attribute
+	node [shape=box]
+	a [label="First " + "vertex", color=red];
+	a -> b -> c [weight=2];
+	subgraph cluster_x {
+		label = "X";
+		edge [style=dashed]
+		c -> d
+	}
+	{e f} -> g:port:n
+	"with \"quotes\"" -> -1.5
+	h [label=<<b>bold</b>>]
+}
+`
+	graph, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if graph.Name != "deps" || !graph.Strict || !graph.Directed {
+		t.Errorf("Wrong header: %q %v %v", graph.Name, graph.Strict, graph.Directed)
+	}
+	if graph.Attributes["rankdir"] != "LR" {
+		t.Errorf("Wrong graph attributes: %v", graph.Attributes)
+	}
+
+	expected := `a->b b->c c->d e->g f->g with "quotes"->-1.5`
+	if got := edgeList(graph.Graph); got != expected {
+		t.Errorf("Expected edges %s, got %s", expected, got)
+	}
+	if graph.Order() != 10 {
+		t.Errorf("Expected 10 vertices, got %d", graph.Order())
+	}
+
+	attrs := graph.VertexAttributes["a"]
+	if attrs["label"] != "First vertex" || attrs["color"] != "red" || attrs["shape"] != "box" {
+		t.Errorf("Wrong attributes for a: %v", attrs)
+	}
+	if attrs := graph.VertexAttributes["h"]; attrs["label"] != "<<b>bold</b>>" {
+		t.Errorf("Wrong attributes for h: %v", attrs)
+	}
+	if attrs := graph.EdgeAttributes[directed.Edge{Source: "a", Target: "b"}]; attrs["weight"] != "2" {
+		t.Errorf("Wrong attributes for a->b: %v", attrs)
+	}
+	if attrs := graph.EdgeAttributes[directed.Edge{Source: "c", Target: "d"}]; attrs["style"] != "dashed" {
+		t.Errorf("Wrong attributes for c->d: %v", attrs)
+	}
+	if attrs := graph.EdgeAttributes[directed.Edge{Source: "e", Target: "g"}]; attrs["style"] != "" {
+		t.Errorf("Edge defaults leaked out of subgraph: %v", attrs)
+	}
+
+	if len(graph.Subgraphs) != 2 {
+		t.Fatalf("Expected 2 subgraphs, got %d", len(graph.Subgraphs))
+	}
+	cluster := graph.Subgraphs[0]
+	if cluster.Name != "cluster_x" || cluster.Attributes["label"] != "X" ||
+		fmt.Sprint(cluster.Vertices) != "[c d]" {
+		t.Errorf("Wrong subgraph: %+v", cluster)
+	}
+}
+
+func TestReadUndirected(t *testing.T) {
+	graph, err := Read(strings.NewReader("graph { a -- b -- a; c -- { d e } }"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if graph.Directed {
+		t.Errorf("Graph read as directed")
+	}
+	expected := "a->b b->a c->d c->e d->c e->c"
+	if got := edgeList(graph.Graph); got != expected {
+		t.Errorf("Expected edges %s, got %s", expected, got)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		input        string
+		line, column int
+	}{
+		{"", 1, 1},
+		{"digraph {\n  a -- b\n}", 2, 5},
+		{"graph {\n  a -> b\n}", 2, 5},
+		{"digraph {\n  a -> \n}", 3, 1},
+		{"digraph {\n  a [color]\n}", 2, 11},
+		{"digraph {\n  a [label=\"open\n}", 2, 12},
+		{"digraph {\n  /* comment\n}", 2, 3},
+		{"digraph { a } b", 1, 15},
+		{"digraph { node; }", 1, 15},
+		{"digraph { a -> 1x }", 1, 17},
+	}
+	for _, tt := range tests {
+		_, err := Read(strings.NewReader(tt.input))
+		serr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Expected syntax error for %q, got %v", tt.input, err)
+			continue
+		}
+		if serr.Line != tt.line || serr.Column != tt.column {
+			t.Errorf("Expected error at %d:%d for %q, got %v",
+				tt.line, tt.column, tt.input, serr)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("plain", `back\slash`)
+	graph.AddEdge(`back\slash`, "new\nline")
+	graph.AddEdge("new\nline", `"quoted"`)
+	graph.AddEdge(`"quoted"`, "plain")
+
+	w := Writer{
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			return Attributes{"tooltip": fmt.Sprint(vertex)}
+		},
+	}
+	var out bytes.Buffer
+	if err := w.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	result, err := Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if got, expected := edgeList(result.Graph), edgeList(graph); got != expected {
+		t.Errorf("Expected edges %s, got %s", expected, got)
+	}
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		if attrs := result.VertexAttributes[vertex]; attrs["tooltip"] != vertex {
+			t.Errorf("Wrong attributes for %q: %v", vertex, attrs)
+		}
+		return nil
+	})
+
+	// Writing the result with the writer of the result should
+	// give the same output.
+	var again bytes.Buffer
+	out.Reset()
+	w.Write(&out, graph)
+	if err := result.Writer().Write(&again, result.Graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if again.String() != out.String() {
+		t.Errorf("Expected:\n%s\ngot:\n%s", out.String(), again.String())
+	}
+}
+
+func TestSerializeErrors(t *testing.T) {
+	graph, err := Read(strings.NewReader(`digraph G { a -> b [label="x"] }`))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if _, err := json.Marshal(graph); !errors.Is(err, ErrAttributes) {
+		t.Errorf("Expected ErrAttributes from json.Marshal, got %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := graph.WriteTo(&buf); !errors.Is(err, ErrAttributes) {
+		t.Errorf("Expected ErrAttributes from WriteTo, got %v", err)
+	}
+
+	// The embedded graph can still be used to save the structure.
+	if _, err := graph.Graph.WriteTo(&buf); err != nil {
+		t.Errorf("WriteTo failed: %v", err)
+	}
+}
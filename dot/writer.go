@@ -0,0 +1,220 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package dot implements reading and writing of graphs in the DOT
+// language used by Graphviz.
+package dot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Attributes is a set of DOT attributes, for example "label",
+// "color", or "shape", and their values.
+type Attributes map[string]string
+
+// Writer writes directed graphs in DOT format. The zero value writes
+// a graph using the string representation of each vertex as its ID.
+// The callbacks can be set to control the IDs of the vertices and the
+// attributes of the vertices and edges.
+type Writer struct {
+	// Name of the graph. If empty, the graph is anonymous.
+	Name string
+
+	// Attributes for the graph as a whole.
+	Attributes Attributes
+
+	// VertexID is called to get the ID of each vertex. The IDs
+	// have to be unique. If nil, the string representation of the
+	// vertex is used.
+	VertexID func(vertex directed.Vertex) string
+
+	// VertexLabel is called to get the label of each vertex. If nil
+	// or if it returns an empty string, no label is written and
+	// Graphviz will use the ID as label.
+	VertexLabel func(vertex directed.Vertex) string
+
+	// VertexAttributes is called to get additional attributes for
+	// each vertex.
+	VertexAttributes func(vertex directed.Vertex) Attributes
+
+	// EdgeAttributes is called to get the attributes for each edge.
+	EdgeAttributes func(source, target directed.Vertex) Attributes
+
+	// Clusters will, if true, place each strongly connected
+	// component with more than one vertex in a cluster subgraph.
+	Clusters bool
+
+	// ClusterAttributes is called to get the attributes of each
+	// cluster, which are numbered from zero.
+	ClusterAttributes func(number int, component *directed.Graph) Attributes
+}
+
+// Quote will return 's' as a quoted DOT string. Double quotes and
+// backslashes are escaped, and newlines are written as "\n". Since
+// Graphviz treats "\r" as a right-justified line break, carriage
+// returns are also written as "\n", except when followed by a newline,
+// in which case they are dropped.
+func Quote(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			if !strings.HasPrefix(s[i+1:], "\n") {
+				builder.WriteString(`\n`)
+			}
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// format will return the attributes in DOT format, sorted by name so
+// that the output is deterministic, or an empty string if there are
+// no attributes.
+func (attrs Attributes) format() string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = Quote(name) + "=" + Quote(attrs[name])
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// vertexID will return the ID of a vertex.
+func (w *Writer) vertexID(vertex directed.Vertex) string {
+	if w.VertexID != nil {
+		return w.VertexID(vertex)
+	}
+	return fmt.Sprint(vertex)
+}
+
+// vertexAttributes will return all the attributes of a vertex,
+// including the label.
+func (w *Writer) vertexAttributes(vertex directed.Vertex) Attributes {
+	attrs := Attributes{}
+	if w.VertexAttributes != nil {
+		for name, value := range w.VertexAttributes(vertex) {
+			attrs[name] = value
+		}
+	}
+	if w.VertexLabel != nil {
+		if label := w.VertexLabel(vertex); label != "" {
+			attrs["label"] = label
+		}
+	}
+	return attrs
+}
+
+// Write will write the graph to 'out' in DOT format. The vertices are
+// written in the order they were added to the graph, followed by the
+// edges. An error is returned if two vertices have the same ID or if
+// writing fails.
+func (w *Writer) Write(out io.Writer, graph *directed.Graph) error {
+	ids := make(map[directed.Vertex]string, graph.Order())
+	owner := make(map[string]directed.Vertex, graph.Order())
+	err := graph.DoVertices(func(vertex directed.Vertex) error {
+		id := w.vertexID(vertex)
+		if other, ok := owner[id]; ok {
+			return fmt.Errorf("dot: vertices %#v and %#v have the same ID %q", other, vertex, id)
+		}
+		owner[id] = vertex
+		ids[vertex] = id
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var clusters []*directed.Graph
+	if w.Clusters {
+		graph.DoCycles(func(component *directed.Graph) error {
+			clusters = append(clusters, component)
+			return nil
+		})
+	}
+	clustered := make(map[directed.Vertex]bool)
+	for _, cluster := range clusters {
+		cluster.DoVertices(func(vertex directed.Vertex) error {
+			clustered[vertex] = true
+			return nil
+		})
+	}
+
+	buf := bufio.NewWriter(out)
+	buf.WriteString("digraph ")
+	if w.Name != "" {
+		buf.WriteString(Quote(w.Name) + " ")
+	}
+	buf.WriteString("{\n")
+	if len(w.Attributes) > 0 {
+		fmt.Fprintf(buf, "\tgraph%s;\n", w.Attributes.format())
+	}
+
+	for number, cluster := range clusters {
+		fmt.Fprintf(buf, "\tsubgraph %s {\n", Quote(fmt.Sprintf("cluster_%d", number)))
+		if w.ClusterAttributes != nil {
+			if attrs := w.ClusterAttributes(number, cluster); len(attrs) > 0 {
+				fmt.Fprintf(buf, "\t\tgraph%s;\n", attrs.format())
+			}
+		}
+		// Write the vertices in the same order as in the graph.
+		graph.DoVertices(func(vertex directed.Vertex) error {
+			if cluster.HasVertex(vertex) {
+				fmt.Fprintf(buf, "\t\t%s%s;\n", Quote(ids[vertex]), w.vertexAttributes(vertex).format())
+			}
+			return nil
+		})
+		buf.WriteString("\t}\n")
+	}
+
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		if !clustered[vertex] {
+			fmt.Fprintf(buf, "\t%s%s;\n", Quote(ids[vertex]), w.vertexAttributes(vertex).format())
+		}
+		return nil
+	})
+
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		var attrs Attributes
+		if w.EdgeAttributes != nil {
+			attrs = w.EdgeAttributes(source, target)
+		}
+		fmt.Fprintf(buf, "\t%s -> %s%s;\n", Quote(ids[source]), Quote(ids[target]), attrs.format())
+		return nil
+	})
+
+	buf.WriteString("}\n")
+	return buf.Flush()
+}
+
+// Write will write the graph to 'out' in DOT format using the string
+// representation of each vertex as its ID.
+func Write(out io.Writer, graph *directed.Graph) error {
+	var w Writer
+	return w.Write(out, graph)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package dot
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		``:             `""`,
+		`abc`:          `"abc"`,
+		`say "hi"`:     `"say \"hi\""`,
+		`C:\dir`:       `"C:\\dir"`,
+		"two\nlines":   `"two\nlines"`,
+		"crlf\r\nend":  `"crlf\nend"`,
+		"cr\rend":      `"cr\nend"`,
+		`trailing \`:   `"trailing \\"`,
+		`unicode: åäö`: `"unicode: åäö"`,
+	}
+	for input, expected := range tests {
+		if got := Quote(input); got != expected {
+			t.Errorf("Quote(%q) = %s, expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", `"c"`)
+	graph.AddVertex("d")
+
+	var out bytes.Buffer
+	if err := Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expected := `digraph {
+	"a";
+	"b";
+	"\"c\"";
+	"d";
+	"a" -> "b";
+	"b" -> "\"c\"";
+}
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteHooks(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(3, 2)
+	graph.AddEdge(3, 4)
+
+	w := Writer{
+		Name:       "deps",
+		Attributes: Attributes{"rankdir": "LR"},
+		VertexID: func(vertex directed.Vertex) string {
+			return fmt.Sprintf("v%d", vertex)
+		},
+		VertexLabel: func(vertex directed.Vertex) string {
+			return fmt.Sprintf("Vertex %d", vertex)
+		},
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			if vertex == 1 {
+				return Attributes{"shape": "box"}
+			}
+			return nil
+		},
+		EdgeAttributes: func(source, target directed.Vertex) Attributes {
+			if source == 3 && target == 4 {
+				return Attributes{"color": "red", "style": "dashed"}
+			}
+			return nil
+		},
+		Clusters: true,
+		ClusterAttributes: func(number int, component *directed.Graph) Attributes {
+			return Attributes{"label": fmt.Sprintf("cycle of %d", component.Order())}
+		},
+	}
+
+	var out bytes.Buffer
+	if err := w.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expected := `digraph "deps" {
+	graph ["rankdir"="LR"];
+	subgraph "cluster_0" {
+		graph ["label"="cycle of 2"];
+		"v2" ["label"="Vertex 2"];
+		"v3" ["label"="Vertex 3"];
+	}
+	"v1" ["label"="Vertex 1", "shape"="box"];
+	"v4" ["label"="Vertex 4"];
+	"v1" -> "v2";
+	"v2" -> "v3";
+	"v3" -> "v2";
+	"v3" -> "v4" ["color"="red", "style"="dashed"];
+}
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteDuplicateID(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge(1, "1")
+
+	var out bytes.Buffer
+	if err := Write(&out, graph); err == nil {
+		t.Errorf("Expected error for duplicate IDs")
+	}
+}
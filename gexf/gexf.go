@@ -0,0 +1,409 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package gexf implements reading and writing of graphs in the Graph
+// Exchange XML Format (GEXF) used by Gephi and NetworkX, including
+// dynamic graphs where vertices, edges, and attribute values only
+// exist during some time intervals.
+package gexf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Namespaces used when writing documents. Documents using earlier
+// versions of the format can also be read.
+const (
+	Namespace    = "http://gexf.net/1.3"
+	VizNamespace = "http://gexf.net/1.3/viz"
+)
+
+// Attributes are the attribute values of a vertex or an edge, keyed
+// by the title of the attribute. The values are bool, int, int64,
+// float32, float64, or string depending on the type of the attribute.
+type Attributes map[string]interface{}
+
+// Attribute is the declaration of an attribute of the vertices or
+// the edges. The type is one of the GEXF types, such as "integer",
+// "double", or "string", and the default, if not nil, has the Go type
+// corresponding to the type.
+type Attribute struct {
+	ID      string
+	Title   string
+	Type    string
+	Default interface{}
+}
+
+// Spell is a time interval. The start and end are in the time format
+// of the graph and an empty start or end means that the interval is
+// unbounded in that direction.
+type Spell struct {
+	Start, End string
+}
+
+// Value is an attribute value that only holds during a time interval.
+type Value struct {
+	Spell
+	Value interface{}
+}
+
+// Position is the position of a vertex in a visualization.
+type Position struct {
+	X, Y, Z float64
+}
+
+// Color is the color of a vertex or an edge. A is the alpha, from
+// zero for transparent to one for opaque.
+type Color struct {
+	R, G, B uint8
+	A       float64
+}
+
+// Viz is the visualization data of a vertex or an edge. For edges,
+// the size is the thickness of the edge. Zero values are not written.
+type Viz struct {
+	Position *Position
+	Color    *Color
+	Size     float64
+	Shape    string
+}
+
+// Element is the data of a vertex or an edge.
+type Element struct {
+	Label string
+
+	// Start and End are the interval when the element exists.
+	// Elements existing in several intervals have spells
+	// instead.
+	Start, End string
+	Spells     []Spell
+
+	// Attributes are the static attribute values and Dynamic the
+	// values that only hold during a time interval.
+	Attributes Attributes
+	Dynamic    map[string][]Value
+
+	Viz Viz
+
+	// Weight of an edge, which is 1 if not given. Weights of 0 and
+	// 1 are not written, so an element without a weight, such as
+	// the zero value, gives an edge with the default weight.
+	Weight float64
+}
+
+// dynamic will check if the element has any time information.
+func (elem *Element) dynamic() bool {
+	return elem.Start != "" || elem.End != "" || len(elem.Spells) > 0 || len(elem.Dynamic) > 0
+}
+
+// Meta is the metadata of a document.
+type Meta struct {
+	LastModified string
+	Creator      string
+	Description  string
+	Keywords     string
+}
+
+// Graph is a graph read from GEXF together with the data of the
+// document, its vertices, and its edges. The vertices are the IDs of
+// the nodes.
+type Graph struct {
+	*directed.Graph
+
+	Meta Meta
+
+	// Directed is false if the default type of the edges was
+	// undirected, in which case each undirected edge was added in
+	// both directions.
+	Directed bool
+
+	// Mode is "static" or "dynamic" and TimeFormat the format of
+	// the times of a dynamic graph, such as "double" or "date".
+	Mode       string
+	TimeFormat string
+
+	// Start and End are the interval covered by the graph.
+	Start, End string
+
+	// NodeAttributes and EdgeAttributes are the attributes
+	// declared for the vertices and edges.
+	NodeAttributes []Attribute
+	EdgeAttributes []Attribute
+
+	// Vertices and Edges are the data of each vertex and edge. For
+	// undirected edges, both directions share the same data.
+	Vertices map[directed.Vertex]*Element
+	Edges    map[directed.Edge]*Element
+}
+
+// ErrAttributes is returned when trying to save or load the graph
+// using the JSON Graph Format or a binary snapshot, which cannot hold
+// the data of the document and its elements. The methods of the embedded graph can be used
+// to save or load only the vertices and edges.
+var ErrAttributes = errors.New("gexf: graph has attributes that cannot be saved, use Writer")
+
+// MarshalJSON will return ErrAttributes.
+func (graph *Graph) MarshalJSON() ([]byte, error) {
+	return nil, ErrAttributes
+}
+
+// UnmarshalJSON will return ErrAttributes.
+func (graph *Graph) UnmarshalJSON(data []byte) error {
+	return ErrAttributes
+}
+
+// WriteTo will return ErrAttributes.
+func (graph *Graph) WriteTo(out io.Writer) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// WriteWeightedTo will return ErrAttributes.
+func (graph *Graph) WriteWeightedTo(out io.Writer, weight directed.WeightFunc) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// ReadFrom will return ErrAttributes.
+func (graph *Graph) ReadFrom(in io.Reader) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// ReadWeightedFrom will return ErrAttributes.
+func (graph *Graph) ReadWeightedFrom(in io.Reader) (directed.Weights, int64, error) {
+	return nil, 0, ErrAttributes
+}
+
+// Weight will return the weight of an edge, or 1 if the edge has no
+// data.
+func (graph *Graph) Weight(source, target directed.Vertex) float64 {
+	if elem, ok := graph.Edges[directed.Edge{Source: source, Target: target}]; ok {
+		return elem.Weight
+	}
+	return 1
+}
+
+// Writer will return a writer that writes the graph with the data
+// that was read, as a directed or undirected graph depending on how
+// it was read.
+func (graph *Graph) Writer() *Writer {
+	return &Writer{
+		Meta:           graph.Meta,
+		Undirected:     !graph.Directed,
+		TimeFormat:     graph.TimeFormat,
+		Start:          graph.Start,
+		End:            graph.End,
+		NodeAttributes: graph.NodeAttributes,
+		EdgeAttributes: graph.EdgeAttributes,
+		Vertex: func(vertex directed.Vertex) *Element {
+			return graph.Vertices[vertex]
+		},
+		Edge: func(source, target directed.Vertex) *Element {
+			return graph.Edges[directed.Edge{Source: source, Target: target}]
+		},
+	}
+}
+
+// typeOf will return the GEXF type of a value, or the empty string if
+// the value cannot be written.
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case int:
+		return "integer"
+	case int64:
+		return "long"
+	case float32:
+		return "float"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	}
+	return ""
+}
+
+// matches will check if a value has the Go type that values of the
+// given GEXF type are read as.
+func matches(typ string, value interface{}) bool {
+	switch value.(type) {
+	case bool:
+		return typ == "boolean"
+	case int:
+		return typ == "integer" || typ == "short" || typ == "byte"
+	case int64:
+		return typ == "long"
+	case float32:
+		return typ == "float"
+	case float64:
+		return typ == "double"
+	case string:
+		_, err := parse(typ, "")
+		return err == nil
+	}
+	return false
+}
+
+// format will return the text representation of a value.
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// parse will parse the text representation of a value of the given
+// type. Types without a matching Go type, such as "date" and
+// "liststring", are kept as strings.
+func parse(typ, text string) (interface{}, error) {
+	switch typ {
+	case "boolean":
+		return strconv.ParseBool(text)
+	case "integer", "short", "byte":
+		return strconv.Atoi(text)
+	case "long":
+		return strconv.ParseInt(text, 10, 64)
+	case "float":
+		f, err := strconv.ParseFloat(text, 32)
+		return float32(f), err
+	case "double":
+		return strconv.ParseFloat(text, 64)
+	}
+	return text, nil
+}
+
+// The XML structure of a document. The elements of the visualization
+// namespace have an XMLName so that the namespace can be set when
+// writing while elements in any namespace are accepted when reading.
+
+type xmlDocument struct {
+	XMLName xml.Name
+	Version string   `xml:"version,attr,omitempty"`
+	Meta    *xmlMeta `xml:"meta"`
+	Graph   xmlGraph `xml:"graph"`
+}
+
+type xmlMeta struct {
+	LastModified string `xml:"lastmodifieddate,attr,omitempty"`
+	Creator      string `xml:"creator,omitempty"`
+	Description  string `xml:"description,omitempty"`
+	Keywords     string `xml:"keywords,omitempty"`
+}
+
+type xmlGraph struct {
+	Mode            string          `xml:"mode,attr,omitempty"`
+	DefaultEdgeType string          `xml:"defaultedgetype,attr,omitempty"`
+	TimeFormat      string          `xml:"timeformat,attr,omitempty"`
+	Start           string          `xml:"start,attr,omitempty"`
+	End             string          `xml:"end,attr,omitempty"`
+	Attributes      []xmlAttributes `xml:"attributes"`
+	Nodes           xmlNodes        `xml:"nodes"`
+	Edges           xmlEdges        `xml:"edges"`
+}
+
+type xmlAttributes struct {
+	Class      string         `xml:"class,attr"`
+	Mode       string         `xml:"mode,attr,omitempty"`
+	Attributes []xmlAttribute `xml:"attribute"`
+}
+
+type xmlAttribute struct {
+	ID      string  `xml:"id,attr"`
+	Title   string  `xml:"title,attr"`
+	Type    string  `xml:"type,attr"`
+	Default *string `xml:"default"`
+}
+
+type xmlAttValues struct {
+	AttValues []xmlAttValue `xml:"attvalue"`
+}
+
+type xmlAttValue struct {
+	For       string `xml:"for,attr"`
+	Value     string `xml:"value,attr"`
+	Start     string `xml:"start,attr,omitempty"`
+	End       string `xml:"end,attr,omitempty"`
+	Timestamp string `xml:"timestamp,attr,omitempty"`
+}
+
+type xmlSpells struct {
+	Spells []xmlSpell `xml:"spell"`
+}
+
+type xmlSpell struct {
+	Start     string `xml:"start,attr,omitempty"`
+	End       string `xml:"end,attr,omitempty"`
+	Timestamp string `xml:"timestamp,attr,omitempty"`
+}
+
+type xmlNodes struct {
+	Nodes []xmlNode `xml:"node"`
+}
+
+type xmlNode struct {
+	ID        string        `xml:"id,attr"`
+	Label     string        `xml:"label,attr,omitempty"`
+	Start     string        `xml:"start,attr,omitempty"`
+	End       string        `xml:"end,attr,omitempty"`
+	AttValues *xmlAttValues `xml:"attvalues"`
+	Spells    *xmlSpells    `xml:"spells"`
+	Color     *xmlColor     `xml:"color"`
+	Position  *xmlPosition  `xml:"position"`
+	Size      *xmlValue     `xml:"size"`
+	Shape     *xmlShape     `xml:"shape"`
+}
+
+type xmlEdges struct {
+	Edges []xmlEdge `xml:"edge"`
+}
+
+type xmlEdge struct {
+	ID        string        `xml:"id,attr,omitempty"`
+	Source    string        `xml:"source,attr"`
+	Target    string        `xml:"target,attr"`
+	Type      string        `xml:"type,attr,omitempty"`
+	Label     string        `xml:"label,attr,omitempty"`
+	Weight    string        `xml:"weight,attr,omitempty"`
+	Start     string        `xml:"start,attr,omitempty"`
+	End       string        `xml:"end,attr,omitempty"`
+	AttValues *xmlAttValues `xml:"attvalues"`
+	Spells    *xmlSpells    `xml:"spells"`
+	Color     *xmlColor     `xml:"color"`
+	Thickness *xmlValue     `xml:"thickness"`
+	Shape     *xmlShape     `xml:"shape"`
+}
+
+type xmlColor struct {
+	XMLName xml.Name
+	R       uint8    `xml:"r,attr"`
+	G       uint8    `xml:"g,attr"`
+	B       uint8    `xml:"b,attr"`
+	A       *float64 `xml:"a,attr"`
+}
+
+type xmlPosition struct {
+	XMLName xml.Name
+	X       float64 `xml:"x,attr"`
+	Y       float64 `xml:"y,attr"`
+	Z       float64 `xml:"z,attr,omitempty"`
+}
+
+type xmlValue struct {
+	XMLName xml.Name
+	Value   float64 `xml:"value,attr"`
+}
+
+type xmlShape struct {
+	XMLName xml.Name
+	Value   string `xml:"value,attr"`
+}
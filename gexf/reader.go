@@ -0,0 +1,215 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package gexf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// reader keeps track of the declared attributes while reading a
+// document.
+type reader struct {
+	node, edge map[string]*Attribute
+}
+
+// spell will convert a time interval, where a timestamp is an
+// interval that starts and ends at the same time.
+func spell(start, end, timestamp string) Spell {
+	if timestamp != "" {
+		return Spell{Start: timestamp, End: timestamp}
+	}
+	return Spell{Start: start, End: end}
+}
+
+// element will convert the common parts of a node or an edge.
+func (r *reader) element(decls map[string]*Attribute, label, start, end string,
+	values *xmlAttValues, spells *xmlSpells) (*Element, error) {
+	elem := &Element{Label: label, Start: start, End: end, Attributes: Attributes{}, Weight: 1}
+	if spells != nil {
+		for _, s := range spells.Spells {
+			elem.Spells = append(elem.Spells, spell(s.Start, s.End, s.Timestamp))
+		}
+	}
+	if values == nil {
+		return elem, nil
+	}
+	for _, v := range values.AttValues {
+		decl, ok := decls[v.For]
+		if !ok {
+			return nil, fmt.Errorf("gexf: value for undeclared attribute %q", v.For)
+		}
+		value, err := parse(decl.Type, v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("gexf: bad value %q for attribute %q: %v", v.Value, decl.Title, err)
+		}
+		if v.Start == "" && v.End == "" && v.Timestamp == "" {
+			elem.Attributes[decl.Title] = value
+			continue
+		}
+		if elem.Dynamic == nil {
+			elem.Dynamic = make(map[string][]Value)
+		}
+		elem.Dynamic[decl.Title] = append(elem.Dynamic[decl.Title],
+			Value{Spell: spell(v.Start, v.End, v.Timestamp), Value: value})
+	}
+	return elem, nil
+}
+
+// color will convert the color of a node or an edge, where a missing
+// alpha means opaque.
+func color(xc *xmlColor) *Color {
+	if xc == nil {
+		return nil
+	}
+	c := &Color{R: xc.R, G: xc.G, B: xc.B, A: 1}
+	if xc.A != nil {
+		c.A = *xc.A
+	}
+	return c
+}
+
+// Read will read a graph in GEXF format from 'in'. Edges are
+// undirected unless the graph or the edge says otherwise, and
+// undirected and mutual edges are added in both directions. Parallel
+// edges are merged, keeping the data of the last edge.
+//
+// The attribute values are converted to Go values according to the
+// type of the attribute. Values with a time interval are kept among
+// the dynamic values of the element, and timestamps are read as
+// intervals that start and end at the same time. Default values are
+// recorded in the declarations, but not added to the attributes of
+// each vertex and edge.
+func Read(in io.Reader) (*Graph, error) {
+	var doc xmlDocument
+	if err := xml.NewDecoder(in).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.XMLName.Local != "gexf" {
+		return nil, fmt.Errorf("gexf: document element is %q, not gexf", doc.XMLName.Local)
+	}
+
+	xg := doc.Graph
+	graph := &Graph{
+		Graph:      directed.New(),
+		Mode:       xg.Mode,
+		TimeFormat: xg.TimeFormat,
+		Start:      xg.Start,
+		End:        xg.End,
+		Vertices:   make(map[directed.Vertex]*Element),
+		Edges:      make(map[directed.Edge]*Element),
+	}
+	if graph.Mode == "" {
+		graph.Mode = "static"
+	}
+	if doc.Meta != nil {
+		graph.Meta = Meta{
+			LastModified: doc.Meta.LastModified,
+			Creator:      doc.Meta.Creator,
+			Description:  doc.Meta.Description,
+			Keywords:     doc.Meta.Keywords,
+		}
+	}
+
+	for _, xa := range xg.Attributes {
+		var list *[]Attribute
+		switch xa.Class {
+		case "node":
+			list = &graph.NodeAttributes
+		case "edge":
+			list = &graph.EdgeAttributes
+		default:
+			return nil, fmt.Errorf("gexf: unknown attribute class %q", xa.Class)
+		}
+		for _, x := range xa.Attributes {
+			attr := Attribute{ID: x.ID, Title: x.Title, Type: x.Type}
+			if attr.Title == "" {
+				attr.Title = attr.ID
+			}
+			if x.Default != nil {
+				value, err := parse(attr.Type, *x.Default)
+				if err != nil {
+					return nil, fmt.Errorf("gexf: bad default %q for attribute %q: %v", *x.Default, attr.Title, err)
+				}
+				attr.Default = value
+			}
+			*list = append(*list, attr)
+		}
+	}
+	r := &reader{node: make(map[string]*Attribute), edge: make(map[string]*Attribute)}
+	for i := range graph.NodeAttributes {
+		r.node[graph.NodeAttributes[i].ID] = &graph.NodeAttributes[i]
+	}
+	for i := range graph.EdgeAttributes {
+		r.edge[graph.EdgeAttributes[i].ID] = &graph.EdgeAttributes[i]
+	}
+
+	for _, xn := range xg.Nodes.Nodes {
+		if _, seen := graph.Vertices[xn.ID]; seen {
+			return nil, fmt.Errorf("gexf: duplicate node %q", xn.ID)
+		}
+		elem, err := r.element(r.node, xn.Label, xn.Start, xn.End, xn.AttValues, xn.Spells)
+		if err != nil {
+			return nil, err
+		}
+		elem.Viz.Color = color(xn.Color)
+		if xn.Position != nil {
+			elem.Viz.Position = &Position{X: xn.Position.X, Y: xn.Position.Y, Z: xn.Position.Z}
+		}
+		if xn.Size != nil {
+			elem.Viz.Size = xn.Size.Value
+		}
+		if xn.Shape != nil {
+			elem.Viz.Shape = xn.Shape.Value
+		}
+		graph.AddVertex(xn.ID)
+		graph.Vertices[xn.ID] = elem
+	}
+
+	defaultType := xg.DefaultEdgeType
+	if defaultType == "" {
+		defaultType = "undirected"
+	}
+	graph.Directed = defaultType == "directed"
+	for _, xe := range xg.Edges.Edges {
+		elem, err := r.element(r.edge, xe.Label, xe.Start, xe.End, xe.AttValues, xe.Spells)
+		if err != nil {
+			return nil, err
+		}
+		if xe.Weight != "" {
+			if elem.Weight, err = strconv.ParseFloat(xe.Weight, 64); err != nil {
+				return nil, fmt.Errorf("gexf: bad weight %q of edge %q", xe.Weight, xe.ID)
+			}
+		}
+		elem.Viz.Color = color(xe.Color)
+		if xe.Thickness != nil {
+			elem.Viz.Size = xe.Thickness.Value
+		}
+		if xe.Shape != nil {
+			elem.Viz.Shape = xe.Shape.Value
+		}
+
+		edgeType := xe.Type
+		if edgeType == "" {
+			edgeType = defaultType
+		}
+		graph.AddEdge(xe.Source, xe.Target)
+		graph.Edges[directed.Edge{Source: xe.Source, Target: xe.Target}] = elem
+		switch edgeType {
+		case "directed":
+		case "undirected", "mutual":
+			graph.AddEdge(xe.Target, xe.Source)
+			graph.Edges[directed.Edge{Source: xe.Target, Target: xe.Source}] = elem
+		default:
+			return nil, fmt.Errorf("gexf: unknown type %q of edge %q", edgeType, xe.ID)
+		}
+	}
+	return graph, nil
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package gexf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// readFile will read a graph from a file in the testdata directory.
+func readFile(t *testing.T, name string) *Graph {
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer file.Close()
+	graph, err := Read(file)
+	if err != nil {
+		t.Fatalf("Read of %s failed: %v", name, err)
+	}
+	return graph
+}
+
+func TestReadHello(t *testing.T) {
+	graph := readFile(t, "hello.gexf")
+	if graph.Order() != 2 || graph.Size() != 1 || !graph.HasEdge("0", "1") {
+		t.Errorf("Wrong graph with %d vertices and %d edges", graph.Order(), graph.Size())
+	}
+	expected := Meta{LastModified: "2009-03-20", Creator: "Gexf.net", Description: "A hello world! file"}
+	if graph.Meta != expected {
+		t.Errorf("Expected meta %+v, got %+v", expected, graph.Meta)
+	}
+	if label := graph.Vertices["1"].Label; label != "Word" {
+		t.Errorf("Wrong label %q", label)
+	}
+	if w := graph.Weight("0", "1"); w != 1 {
+		t.Errorf("Expected default weight 1, got %v", w)
+	}
+}
+
+func TestReadData(t *testing.T) {
+	graph := readFile(t, "data.gexf")
+	if graph.Mode != "static" || graph.Order() != 4 || graph.Size() != 5 {
+		t.Errorf("Wrong %s graph with %d vertices and %d edges", graph.Mode, graph.Order(), graph.Size())
+	}
+	expected := []Attribute{
+		{ID: "0", Title: "url", Type: "string"},
+		{ID: "1", Title: "indegree", Type: "float"},
+		{ID: "2", Title: "frog", Type: "boolean", Default: true},
+	}
+	if !reflect.DeepEqual(graph.NodeAttributes, expected) {
+		t.Errorf("Expected declarations %v, got %v", expected, graph.NodeAttributes)
+	}
+	attrs := Attributes{"url": "http://barabasilab.com", "indegree": float32(1), "frog": false}
+	if !reflect.DeepEqual(graph.Vertices["3"].Attributes, attrs) {
+		t.Errorf("Expected attributes %v, got %v", attrs, graph.Vertices["3"].Attributes)
+	}
+}
+
+func TestReadDynamic(t *testing.T) {
+	graph := readFile(t, "dynamic.gexf")
+	if graph.Mode != "dynamic" || graph.TimeFormat != "date" || graph.Start != "2009-01-01" || graph.End != "2009-03-20" {
+		t.Errorf("Wrong graph %s %s %s-%s", graph.Mode, graph.TimeFormat, graph.Start, graph.End)
+	}
+	gephi := graph.Vertices["0"]
+	if gephi.Start != "2009-03-01" || gephi.End != "" {
+		t.Errorf("Wrong interval %s-%s", gephi.Start, gephi.End)
+	}
+	if url := gephi.Attributes["url"]; url != "http://gephi.org" {
+		t.Errorf("Wrong static value %v", url)
+	}
+	values := []Value{
+		{Spell: Spell{Start: "2009-03-01", End: "2009-03-10"}, Value: 1},
+		{Spell: Spell{Start: "2009-03-10"}, Value: 2},
+	}
+	if !reflect.DeepEqual(gephi.Dynamic["indegree"], values) {
+		t.Errorf("Expected dynamic values %v, got %v", values, gephi.Dynamic["indegree"])
+	}
+	spells := []Spell{{"2009-01-01", "2009-01-15"}, {"2009-01-30", "2009-02-01"}}
+	if !reflect.DeepEqual(graph.Vertices["1"].Spells, spells) {
+		t.Errorf("Expected spells %v, got %v", spells, graph.Vertices["1"].Spells)
+	}
+	if w := graph.Weight("1", "2"); w != 3.5 {
+		t.Errorf("Expected weight 3.5, got %v", w)
+	}
+	if !graph.HasEdge("0", "2") || graph.HasEdge("1", "0") {
+		t.Errorf("Wrong edge directions")
+	}
+}
+
+func TestReadViz(t *testing.T) {
+	graph := readFile(t, "viz.gexf")
+	expected := Viz{
+		Position: &Position{X: 15.783598, Y: 40.109245},
+		Color:    &Color{R: 239, G: 173, B: 66, A: 0.6},
+		Size:     2.0375757,
+		Shape:    "disc",
+	}
+	if !reflect.DeepEqual(graph.Vertices["a"].Viz, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, graph.Vertices["a"].Viz)
+	}
+	if c := graph.Vertices["b"].Viz.Color; c.A != 1 {
+		t.Errorf("Expected opaque color, got %+v", c)
+	}
+	if graph.Directed {
+		t.Errorf("Expected undirected graph")
+	}
+	edge := graph.Edges[directed.Edge{Source: "b", Target: "a"}]
+	if edge == nil || edge.Viz.Size != 3 || edge.Viz.Shape != "dashed" || edge.Weight != 0.5 {
+		t.Errorf("Wrong undirected edge %+v", edge)
+	}
+}
+
+func TestReadNetworkX(t *testing.T) {
+	graph := readFile(t, "networkx.gexf")
+	if graph.Order() != 3 || graph.Size() != 2 {
+		t.Errorf("Wrong graph with %d vertices and %d edges", graph.Order(), graph.Size())
+	}
+	if age := graph.Vertices["bob"].Attributes["age"]; age != int64(42) {
+		t.Errorf("Wrong age %#v", age)
+	}
+	edge := graph.Edges[directed.Edge{Source: "alice", Target: "bob"}]
+	if edge.Weight != 2 || edge.Attributes["relation"] != "friend" {
+		t.Errorf("Wrong edge %+v", edge)
+	}
+}
+
+// TestConformance will check that each sample file is unchanged by
+// writing it and reading it back.
+func TestConformance(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("testdata", "*.gexf"))
+	if err != nil || len(names) == 0 {
+		t.Fatalf("No sample files: %v", err)
+	}
+	for _, name := range names {
+		graph := readFile(t, filepath.Base(name))
+		original, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := graph.Writer().Write(&buf, graph.Graph); err != nil {
+			t.Errorf("Write of %s failed: %v", name, err)
+			continue
+		}
+		if got, expected := strings.Count(buf.String(), "<edge "), bytes.Count(original, []byte("<edge ")); got > expected {
+			t.Errorf("%s: expected at most %d edges written, got %d", name, expected, got)
+		}
+		result, err := Read(&buf)
+		if err != nil {
+			t.Errorf("Read of written %s failed: %v", name, err)
+			continue
+		}
+		if result.Directed != graph.Directed {
+			t.Errorf("%s: expected directed %v, got %v", name, graph.Directed, result.Directed)
+		}
+		if result.Order() != graph.Order() || result.Size() != graph.Size() {
+			t.Errorf("%s: expected %d vertices and %d edges, got %d and %d", name,
+				graph.Order(), graph.Size(), result.Order(), result.Size())
+		}
+		if result.Meta != graph.Meta || result.Mode != graph.Mode || result.TimeFormat != graph.TimeFormat ||
+			result.Start != graph.Start || result.End != graph.End {
+			t.Errorf("%s: graph data differs", name)
+		}
+		if !reflect.DeepEqual(result.NodeAttributes, graph.NodeAttributes) ||
+			!reflect.DeepEqual(result.EdgeAttributes, graph.EdgeAttributes) {
+			t.Errorf("%s: declarations differ", name)
+		}
+		if !reflect.DeepEqual(result.Vertices, graph.Vertices) {
+			t.Errorf("%s: vertex data differs", name)
+		}
+		if !reflect.DeepEqual(result.Edges, graph.Edges) {
+			t.Errorf("%s: edge data differs", name)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	inputs := []string{
+		``,
+		`<graphml/>`,
+		`<gexf><graph><nodes><node id="a"/><node id="a"/></nodes></graph></gexf>`,
+		`<gexf><graph><attributes class="graph"/></graph></gexf>`,
+		`<gexf><graph><nodes><node id="a"><attvalues><attvalue for="0" value="1"/></attvalues></node></nodes></graph></gexf>`,
+		`<gexf><graph><attributes class="node"><attribute id="0" title="n" type="integer"/></attributes>
+		 <nodes><node id="a"><attvalues><attvalue for="0" value="x"/></attvalues></node></nodes></graph></gexf>`,
+		`<gexf><graph><attributes class="node"><attribute id="0" title="b" type="boolean"><default>maybe</default></attribute></attributes></graph></gexf>`,
+		`<gexf><graph><edges><edge source="a" target="b" weight="heavy"/></edges></graph></gexf>`,
+		`<gexf><graph><edges><edge source="a" target="b" type="sideways"/></edges></graph></gexf>`,
+	}
+	for _, input := range inputs {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestSerializeErrors(t *testing.T) {
+	graph, err := Read(strings.NewReader(`<gexf><graph><nodes><node id="a"/><node id="b"/></nodes><edges><edge source="a" target="b"/></edges></graph></gexf>`))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if _, err := json.Marshal(graph); !errors.Is(err, ErrAttributes) {
+		t.Errorf("Expected ErrAttributes from json.Marshal, got %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := graph.WriteTo(&buf); !errors.Is(err, ErrAttributes) {
+		t.Errorf("Expected ErrAttributes from WriteTo, got %v", err)
+	}
+
+	// The embedded graph can still be used to save the structure.
+	if _, err := graph.Graph.WriteTo(&buf); err != nil {
+		t.Errorf("WriteTo failed: %v", err)
+	}
+}
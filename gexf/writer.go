@@ -0,0 +1,371 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package gexf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Writer writes directed graphs in GEXF format. The zero value writes
+// a graph using the string representation of each vertex as its ID
+// and without any data.
+//
+// Attributes are declared automatically using the type of the values,
+// so values read back have the same types as the values written. The
+// graph is written as dynamic if the graph or any vertex or edge has
+// time information.
+type Writer struct {
+	Meta Meta
+
+	// Undirected will, if true, write the graph with undirected
+	// edges as default. Each pair of edges in opposite directions
+	// is then written as a single edge, with the data of the edge
+	// written first, while edges without a reverse edge are written
+	// as directed edges. If false, a pair of edges is written as a
+	// single undirected edge only if Edge returns the same data for
+	// both edges.
+	Undirected bool
+
+	// TimeFormat is the format of the times of a dynamic graph,
+	// such as "double", "integer", "date", or "dateTime".
+	TimeFormat string
+
+	// Start and End are the interval covered by the graph.
+	Start, End string
+
+	// NodeAttributes and EdgeAttributes are attributes to declare
+	// in addition to those declared automatically. This can be
+	// used to give an attribute a default value or a specific ID.
+	NodeAttributes []Attribute
+	EdgeAttributes []Attribute
+
+	// VertexID is called to get the ID of each vertex. The IDs
+	// have to be unique. If nil, the string representation of the
+	// vertex is used.
+	VertexID func(vertex directed.Vertex) string
+
+	// Vertex is called to get the data of each vertex, which can
+	// be nil.
+	Vertex func(vertex directed.Vertex) *Element
+
+	// Edge is called to get the data of each edge, which can be
+	// nil.
+	Edge func(source, target directed.Vertex) *Element
+}
+
+// attrSet keeps track of the attributes declared for one class of
+// elements while writing a document.
+type attrSet struct {
+	class   string
+	dynamic bool
+	attrs   []xmlAttribute
+	types   map[string]string
+	ids     map[string]string
+}
+
+func newAttrSet(class string) *attrSet {
+	return &attrSet{class: class, types: make(map[string]string), ids: make(map[string]string)}
+}
+
+// declare will add an attribute declaration, checking that the ID is
+// unique.
+func (as *attrSet) declare(attr Attribute) error {
+	if _, ok := as.types[attr.ID]; ok {
+		return fmt.Errorf("gexf: duplicate %s attribute ID %q", as.class, attr.ID)
+	}
+	if attr.Type == "" {
+		attr.Type = "string"
+	}
+	xa := xmlAttribute{ID: attr.ID, Title: attr.Title, Type: attr.Type}
+	if attr.Default != nil {
+		if !matches(attr.Type, attr.Default) {
+			return fmt.Errorf("gexf: default %#v does not match type %q of attribute %q",
+				attr.Default, attr.Type, attr.Title)
+		}
+		text := format(attr.Default)
+		xa.Default = &text
+	}
+	as.attrs = append(as.attrs, xa)
+	as.types[attr.ID] = attr.Type
+	as.ids[attr.Title] = attr.ID
+	return nil
+}
+
+// value will convert a single value, declaring the attribute if
+// necessary.
+func (as *attrSet) value(title string, value interface{}) (xmlAttValue, error) {
+	kind := typeOf(value)
+	if kind == "" {
+		return xmlAttValue{}, fmt.Errorf("gexf: unsupported type %T of attribute %q", value, title)
+	}
+	id, ok := as.ids[title]
+	if !ok {
+		id = strconv.Itoa(len(as.attrs))
+		for _, taken := as.types[id]; taken; _, taken = as.types[id] {
+			id += "_"
+		}
+		if err := as.declare(Attribute{ID: id, Title: title, Type: kind}); err != nil {
+			return xmlAttValue{}, err
+		}
+	}
+	if !matches(as.types[id], value) {
+		return xmlAttValue{}, fmt.Errorf("gexf: attribute %q of type %T does not match type %q",
+			title, value, as.types[id])
+	}
+	return xmlAttValue{For: id, Value: format(value)}, nil
+}
+
+// values will convert the static and dynamic attribute values of an
+// element, sorted by title so that the output is deterministic.
+func (as *attrSet) values(elem *Element) (*xmlAttValues, error) {
+	var titles []string
+	for title := range elem.Attributes {
+		titles = append(titles, title)
+	}
+	for title := range elem.Dynamic {
+		if _, ok := elem.Attributes[title]; !ok {
+			titles = append(titles, title)
+		}
+	}
+	sort.Strings(titles)
+
+	var result []xmlAttValue
+	for _, title := range titles {
+		if value, ok := elem.Attributes[title]; ok {
+			xv, err := as.value(title, value)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, xv)
+		}
+		for _, v := range elem.Dynamic[title] {
+			xv, err := as.value(title, v.Value)
+			if err != nil {
+				return nil, err
+			}
+			xv.Start, xv.End = v.Start, v.End
+			result = append(result, xv)
+			as.dynamic = true
+		}
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return &xmlAttValues{AttValues: result}, nil
+}
+
+// spells will convert the spells of an element.
+func spells(elem *Element) *xmlSpells {
+	if len(elem.Spells) == 0 {
+		return nil
+	}
+	result := &xmlSpells{}
+	for _, s := range elem.Spells {
+		result.Spells = append(result.Spells, xmlSpell{Start: s.Start, End: s.End})
+	}
+	return result
+}
+
+// vizColor will convert a color, leaving out the alpha if the color
+// is opaque.
+func vizColor(c *Color) *xmlColor {
+	if c == nil {
+		return nil
+	}
+	xc := &xmlColor{XMLName: xml.Name{Space: VizNamespace, Local: "color"}, R: c.R, G: c.G, B: c.B}
+	if c.A != 1 {
+		a := c.A
+		xc.A = &a
+	}
+	return xc
+}
+
+// vizValue will convert a size or a thickness, which is left out if
+// zero.
+func vizValue(name string, value float64) *xmlValue {
+	if value == 0 {
+		return nil
+	}
+	return &xmlValue{XMLName: xml.Name{Space: VizNamespace, Local: name}, Value: value}
+}
+
+// vizShape will convert a shape, which is left out if empty.
+func vizShape(shape string) *xmlShape {
+	if shape == "" {
+		return nil
+	}
+	return &xmlShape{XMLName: xml.Name{Space: VizNamespace, Local: "shape"}, Value: shape}
+}
+
+// Write will write the graph to 'out' in GEXF format. The vertices
+// are written in the order they were added to the graph, followed by
+// the edges. An error is returned if two vertices have the same ID,
+// if an attribute value does not have a GEXF type, or if writing
+// fails.
+func (w *Writer) Write(out io.Writer, graph *directed.Graph) error {
+	nodeAttrs, edgeAttrs := newAttrSet("node"), newAttrSet("edge")
+	for _, attr := range w.NodeAttributes {
+		if err := nodeAttrs.declare(attr); err != nil {
+			return err
+		}
+	}
+	for _, attr := range w.EdgeAttributes {
+		if err := edgeAttrs.declare(attr); err != nil {
+			return err
+		}
+	}
+
+	dynamic := w.Start != "" || w.End != ""
+	xg := xmlGraph{
+		DefaultEdgeType: "directed",
+		TimeFormat:      w.TimeFormat,
+		Start:           w.Start,
+		End:             w.End,
+	}
+
+	ids := make(map[directed.Vertex]string, graph.Order())
+	owner := make(map[string]directed.Vertex, graph.Order())
+	err := graph.DoVertices(func(vertex directed.Vertex) error {
+		id := fmt.Sprint(vertex)
+		if w.VertexID != nil {
+			id = w.VertexID(vertex)
+		}
+		if other, ok := owner[id]; ok {
+			return fmt.Errorf("gexf: vertices %#v and %#v have the same ID %q", other, vertex, id)
+		}
+		owner[id] = vertex
+		ids[vertex] = id
+
+		xn := xmlNode{ID: id, Label: id}
+		if w.Vertex != nil {
+			if elem := w.Vertex(vertex); elem != nil {
+				values, err := nodeAttrs.values(elem)
+				if err != nil {
+					return err
+				}
+				dynamic = dynamic || elem.dynamic()
+				xn.Label, xn.Start, xn.End = elem.Label, elem.Start, elem.End
+				xn.AttValues, xn.Spells = values, spells(elem)
+				xn.Color = vizColor(elem.Viz.Color)
+				if p := elem.Viz.Position; p != nil {
+					xn.Position = &xmlPosition{
+						XMLName: xml.Name{Space: VizNamespace, Local: "position"},
+						X:       p.X, Y: p.Y, Z: p.Z,
+					}
+				}
+				xn.Size = vizValue("size", elem.Viz.Size)
+				xn.Shape = vizShape(elem.Viz.Shape)
+			}
+		}
+		xg.Nodes.Nodes = append(xg.Nodes.Nodes, xn)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if w.Undirected {
+		xg.DefaultEdgeType = "undirected"
+	}
+	written := make(map[directed.Edge]bool)
+	err = graph.DoEdges(func(source, target directed.Vertex) error {
+		if written[directed.Edge{Source: target, Target: source}] {
+			return nil
+		}
+		xe := xmlEdge{
+			ID:     strconv.Itoa(len(xg.Edges.Edges)),
+			Source: ids[source],
+			Target: ids[target],
+		}
+		var elem *Element
+		if w.Edge != nil {
+			elem = w.Edge(source, target)
+		}
+		pair := graph.HasEdge(target, source)
+		switch {
+		case w.Undirected && pair:
+			written[directed.Edge{Source: source, Target: target}] = true
+		case w.Undirected:
+			xe.Type = "directed"
+		case pair && elem != nil && w.Edge(target, source) == elem:
+			// Both directions share the same data, as for the
+			// undirected edges of a graph that was read.
+			written[directed.Edge{Source: source, Target: target}] = true
+			xe.Type = "undirected"
+		}
+		if elem != nil {
+			values, err := edgeAttrs.values(elem)
+			if err != nil {
+				return err
+			}
+			dynamic = dynamic || elem.dynamic()
+			xe.Label, xe.Start, xe.End = elem.Label, elem.Start, elem.End
+			xe.AttValues, xe.Spells = values, spells(elem)
+			if elem.Weight != 0 && elem.Weight != 1 {
+				xe.Weight = strconv.FormatFloat(elem.Weight, 'g', -1, 64)
+			}
+			xe.Color = vizColor(elem.Viz.Color)
+			xe.Thickness = vizValue("thickness", elem.Viz.Size)
+			xe.Shape = vizShape(elem.Viz.Shape)
+		}
+		xg.Edges.Edges = append(xg.Edges.Edges, xe)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	xg.Mode = "static"
+	if dynamic {
+		xg.Mode = "dynamic"
+	}
+	for _, as := range []*attrSet{nodeAttrs, edgeAttrs} {
+		if len(as.attrs) > 0 {
+			xa := xmlAttributes{Class: as.class, Mode: "static", Attributes: as.attrs}
+			if as.dynamic {
+				xa.Mode = "dynamic"
+			}
+			xg.Attributes = append(xg.Attributes, xa)
+		}
+	}
+
+	doc := xmlDocument{
+		XMLName: xml.Name{Space: Namespace, Local: "gexf"},
+		Version: "1.3",
+		Graph:   xg,
+	}
+	if w.Meta != (Meta{}) {
+		doc.Meta = &xmlMeta{
+			LastModified: w.Meta.LastModified,
+			Creator:      w.Meta.Creator,
+			Description:  w.Meta.Description,
+			Keywords:     w.Meta.Keywords,
+		}
+	}
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\n")
+	return err
+}
+
+// Write will write the graph to 'out' in GEXF format using the
+// default writer.
+func Write(out io.Writer, graph *directed.Graph) error {
+	var w Writer
+	return w.Write(out, graph)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package gexf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func TestWrite(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+
+	w := Writer{
+		Meta:       Meta{Creator: "gograph"},
+		TimeFormat: "double",
+		Vertex: func(vertex directed.Vertex) *Element {
+			switch vertex {
+			case "a":
+				return &Element{
+					Label:      "A",
+					Attributes: Attributes{"size": 3},
+					Viz:        Viz{Position: &Position{X: 1, Y: 2}},
+				}
+			case "b":
+				return &Element{
+					Label: "B",
+					Start: "1.0",
+					Dynamic: map[string][]Value{
+						"size": {{Spell: Spell{Start: "1.0", End: "2.0"}, Value: 5}},
+					},
+				}
+			}
+			return nil
+		},
+		Edge: func(source, target directed.Vertex) *Element {
+			if source == "a" {
+				return &Element{Weight: 2.5, Viz: Viz{Color: &Color{R: 255, A: 0.5}}}
+			}
+			return &Element{Label: "no weight"}
+		},
+	}
+	var out bytes.Buffer
+	if err := w.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" version="1.3">
+  <meta>
+    <creator>gograph</creator>
+  </meta>
+  <graph mode="dynamic" defaultedgetype="directed" timeformat="double">
+    <attributes class="node" mode="dynamic">
+      <attribute id="0" title="size" type="integer"></attribute>
+    </attributes>
+    <nodes>
+      <node id="a" label="A">
+        <attvalues>
+          <attvalue for="0" value="3"></attvalue>
+        </attvalues>
+        <position xmlns="http://gexf.net/1.3/viz" x="1" y="2"></position>
+      </node>
+      <node id="b" label="B" start="1.0">
+        <attvalues>
+          <attvalue for="0" value="5" start="1.0" end="2.0"></attvalue>
+        </attvalues>
+      </node>
+      <node id="c" label="c"></node>
+    </nodes>
+    <edges>
+      <edge id="0" source="a" target="b" weight="2.5">
+        <color xmlns="http://gexf.net/1.3/viz" r="255" g="0" b="0" a="0.5"></color>
+      </edge>
+      <edge id="1" source="b" target="c" label="no weight"></edge>
+    </edges>
+  </graph>
+</gexf>
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	result, err := Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if w := result.Weight("b", "c"); w != 1 {
+		t.Errorf("Expected default weight 1, got %v", w)
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge(1, "1")
+	var out bytes.Buffer
+	if err := Write(&out, graph); err == nil {
+		t.Errorf("Expected error for duplicate IDs")
+	}
+
+	graph = directed.New()
+	graph.AddEdge(1, 2)
+	w := Writer{
+		Vertex: func(vertex directed.Vertex) *Element {
+			if vertex == 1 {
+				return &Element{Attributes: Attributes{"value": 1}}
+			}
+			return &Element{Attributes: Attributes{"value": "two"}}
+		},
+	}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for conflicting types")
+	}
+
+	w = Writer{
+		Edge: func(source, target directed.Vertex) *Element {
+			return &Element{Attributes: Attributes{"bad": []int{1}}}
+		},
+	}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for unsupported type")
+	}
+
+	w = Writer{NodeAttributes: []Attribute{{ID: "0", Title: "n", Type: "integer", Default: "x"}}}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for default of wrong type")
+	}
+}
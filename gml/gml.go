@@ -0,0 +1,121 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package gml implements reading and writing of graphs in the Graph
+// Modelling Language (GML), as used by, for example, NetworkX and
+// igraph.
+package gml
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Attributes are the attributes of a graph, vertex, or edge, keyed by
+// name. The values are int, float64, string, or, for nested lists such
+// as "graphics", Attributes.
+type Attributes map[string]interface{}
+
+// Graph is a graph read from GML together with the attributes of the
+// graph, its vertices, and its edges. The vertices are the values of
+// the "id" keys of the nodes, which are normally ints.
+type Graph struct {
+	*directed.Graph
+
+	// Directed is false if the graph was undirected, in which case
+	// each edge was added in both directions.
+	Directed bool
+
+	// Attributes of the graph, not including the nodes and edges.
+	Attributes Attributes
+
+	// VertexAttributes are the attributes of each vertex, not
+	// including the "id" key.
+	VertexAttributes map[directed.Vertex]Attributes
+
+	// EdgeAttributes are the attributes of each edge, not including
+	// the "source" and "target" keys.
+	EdgeAttributes map[directed.Edge]Attributes
+}
+
+// ErrAttributes is returned when trying to save or load the graph
+// using the JSON Graph Format or a binary snapshot, which cannot hold
+// the attributes. The methods of the embedded graph can be used
+// to save or load only the vertices and edges.
+var ErrAttributes = errors.New("gml: graph has attributes that cannot be saved, use Writer")
+
+// MarshalJSON will return ErrAttributes.
+func (graph *Graph) MarshalJSON() ([]byte, error) {
+	return nil, ErrAttributes
+}
+
+// UnmarshalJSON will return ErrAttributes.
+func (graph *Graph) UnmarshalJSON(data []byte) error {
+	return ErrAttributes
+}
+
+// WriteTo will return ErrAttributes.
+func (graph *Graph) WriteTo(out io.Writer) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// WriteWeightedTo will return ErrAttributes.
+func (graph *Graph) WriteWeightedTo(out io.Writer, weight directed.WeightFunc) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// ReadFrom will return ErrAttributes.
+func (graph *Graph) ReadFrom(in io.Reader) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// ReadWeightedFrom will return ErrAttributes.
+func (graph *Graph) ReadWeightedFrom(in io.Reader) (directed.Weights, int64, error) {
+	return nil, 0, ErrAttributes
+}
+
+// Writer will return a writer that writes the graph with the
+// attributes that were read, as a directed or undirected graph
+// depending on how it was read. If all the vertices are ints, they are
+// used as the IDs of the nodes. Otherwise the nodes are numbered, and
+// nodes without a label are labelled with the original ID.
+func (graph *Graph) Writer() *Writer {
+	var vertexID func(vertex directed.Vertex) int
+	ints := graph.DoVertices(func(vertex directed.Vertex) error {
+		if _, ok := vertex.(int); !ok {
+			return directed.StopWalk
+		}
+		return nil
+	}) == nil
+	if ints {
+		vertexID = func(vertex directed.Vertex) int {
+			return vertex.(int)
+		}
+	}
+	return &Writer{
+		Attributes: graph.Attributes,
+		Undirected: !graph.Directed,
+		VertexID:   vertexID,
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			return graph.VertexAttributes[vertex]
+		},
+		EdgeAttributes: func(source, target directed.Vertex) Attributes {
+			return graph.EdgeAttributes[directed.Edge{Source: source, Target: target}]
+		},
+	}
+}
+
+// SyntaxError is returned when the input is not valid GML.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("gml: line %d: %s", err.Line, err.Msg)
+}
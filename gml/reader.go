@@ -0,0 +1,334 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package gml
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Token kinds returned by the scanner.
+const (
+	tokenEOF = iota
+	tokenKey
+	tokenInt
+	tokenReal
+	tokenString
+	tokenOpen
+	tokenClose
+)
+
+// token is a single token of the input together with the line it
+// started on.
+type token struct {
+	kind int
+	text string
+	line int
+}
+
+// scanner splits GML input into tokens.
+type scanner struct {
+	in   *bufio.Reader
+	line int
+}
+
+func (s *scanner) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Line: s.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next will return the next token, skipping whitespace and comments,
+// which start with '#' and extend to the end of the line.
+func (s *scanner) next() (token, error) {
+	for {
+		r, _, err := s.in.ReadRune()
+		if err == io.EOF {
+			return token{kind: tokenEOF, line: s.line}, nil
+		} else if err != nil {
+			return token{}, err
+		}
+		switch {
+		case r == '\n':
+			s.line++
+		case unicode.IsSpace(r):
+		case r == '#':
+			if _, err := s.in.ReadString('\n'); err != nil && err != io.EOF {
+				return token{}, err
+			}
+			s.line++
+		case r == '[':
+			return token{kind: tokenOpen, text: "[", line: s.line}, nil
+		case r == ']':
+			return token{kind: tokenClose, text: "]", line: s.line}, nil
+		case r == '"':
+			return s.quoted()
+		case r == '_' || unicode.IsLetter(r):
+			s.in.UnreadRune()
+			text, err := s.word()
+			if err != nil {
+				return token{}, err
+			}
+			switch strings.TrimLeft(text, "+-") {
+			case "INF", "NAN":
+				return token{kind: tokenReal, text: text, line: s.line}, nil
+			}
+			return token{kind: tokenKey, text: text, line: s.line}, nil
+		case r == '+' || r == '-' || r == '.' || unicode.IsDigit(r):
+			s.in.UnreadRune()
+			return s.number()
+		default:
+			return token{}, s.errorf("unexpected character %q", r)
+		}
+	}
+}
+
+// word will read a sequence of letters, digits, and underscores.
+func (s *scanner) word() (string, error) {
+	var builder strings.Builder
+	for {
+		r, _, err := s.in.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			s.in.UnreadRune()
+			break
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String(), nil
+}
+
+// number will read an integer or a real number, including the
+// signed INF and NAN written by NetworkX.
+func (s *scanner) number() (token, error) {
+	var builder strings.Builder
+	for {
+		r, _, err := s.in.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return token{}, err
+		}
+		if !strings.ContainsRune("+-.eE_", r) && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			s.in.UnreadRune()
+			break
+		}
+		builder.WriteRune(r)
+	}
+	text := builder.String()
+	if _, err := strconv.Atoi(text); err == nil {
+		return token{kind: tokenInt, text: text, line: s.line}, nil
+	}
+	if _, err := parseReal(text); err != nil {
+		return token{}, s.errorf("bad number %q", text)
+	}
+	return token{kind: tokenReal, text: text, line: s.line}, nil
+}
+
+// quoted will read the rest of a string. Strings cannot contain
+// double quotes, but can span several lines and contain HTML
+// character entities, which are replaced by the characters they
+// stand for.
+func (s *scanner) quoted() (token, error) {
+	line := s.line
+	text, err := s.in.ReadString('"')
+	if err == io.EOF {
+		return token{}, &SyntaxError{Line: line, Msg: "unterminated string"}
+	} else if err != nil {
+		return token{}, err
+	}
+	s.line += strings.Count(text, "\n")
+	text = html.UnescapeString(text[:len(text)-1])
+	return token{kind: tokenString, text: text, line: line}, nil
+}
+
+// parseReal will parse a real number, accepting INF and NAN in the
+// spelling used by NetworkX.
+func parseReal(text string) (float64, error) {
+	switch text {
+	case "INF", "+INF":
+		return math.Inf(1), nil
+	case "-INF":
+		return math.Inf(-1), nil
+	case "NAN", "+NAN", "-NAN":
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// pair is a key and value of a list. The value is an int, float64,
+// string, or []pair.
+type pair struct {
+	key   string
+	value interface{}
+	line  int
+}
+
+// parseList will parse key-value pairs until the end of the input,
+// if 'nested' is false, or until a closing bracket otherwise.
+func (s *scanner) parseList(nested bool) ([]pair, error) {
+	var list []pair
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.kind {
+		case tokenEOF:
+			if nested {
+				return nil, s.errorf("unexpected end of input")
+			}
+			return list, nil
+		case tokenClose:
+			if !nested {
+				return nil, s.errorf("unexpected ']'")
+			}
+			return list, nil
+		case tokenKey:
+		default:
+			return nil, s.errorf("expected key, found %q", tok.text)
+		}
+
+		val, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		item := pair{key: tok.text, line: tok.line}
+		switch val.kind {
+		case tokenInt:
+			item.value, _ = strconv.Atoi(val.text)
+		case tokenReal:
+			item.value, _ = parseReal(val.text)
+		case tokenString:
+			item.value = val.text
+		case tokenOpen:
+			if item.value, err = s.parseList(true); err != nil {
+				return nil, err
+			}
+		case tokenEOF:
+			return nil, s.errorf("missing value for key %q", tok.text)
+		default:
+			return nil, s.errorf("bad value %q for key %q", val.text, tok.text)
+		}
+		list = append(list, item)
+	}
+}
+
+// attributes will convert a list to attributes, recursively
+// converting nested lists. If a key is repeated, the last value is
+// used.
+func attributes(list []pair) Attributes {
+	attrs := Attributes{}
+	for _, item := range list {
+		if nested, ok := item.value.([]pair); ok {
+			attrs[item.key] = attributes(nested)
+		} else {
+			attrs[item.key] = item.value
+		}
+	}
+	return attrs
+}
+
+// Read will read a graph in GML format from 'in'. Only the first
+// graph of the input is read. If the graph is not directed, each edge
+// is added in both directions. Since the graph cannot have parallel
+// edges, the attributes of a repeated edge replace those of the
+// earlier one.
+//
+// Integers are read as int and reals as float64. Strings have HTML
+// character entities, such as "&quot;", replaced.
+func Read(in io.Reader) (*Graph, error) {
+	s := &scanner{in: bufio.NewReader(in), line: 1}
+	top, err := s.parseList(false)
+	if err != nil {
+		return nil, err
+	}
+	var body []pair
+	found := false
+	for _, item := range top {
+		if list, ok := item.value.([]pair); ok && item.key == "graph" {
+			body, found = list, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("gml: no graph in input")
+	}
+
+	graph := &Graph{
+		Graph:            directed.New(),
+		Attributes:       Attributes{},
+		VertexAttributes: make(map[directed.Vertex]Attributes),
+		EdgeAttributes:   make(map[directed.Edge]Attributes),
+	}
+	for _, item := range body {
+		if item.key == "directed" {
+			graph.Directed = item.value == 1
+		}
+	}
+
+	var edges []pair
+	for _, item := range body {
+		list, isList := item.value.([]pair)
+		switch {
+		case item.key == "node" && isList:
+			attrs := attributes(list)
+			id, ok := attrs["id"]
+			if !ok {
+				return nil, &SyntaxError{Line: item.line, Msg: "node without id"}
+			}
+			if _, isList := id.(Attributes); isList {
+				return nil, &SyntaxError{Line: item.line, Msg: "node id is a list"}
+			}
+			if _, seen := graph.VertexAttributes[id]; seen {
+				return nil, &SyntaxError{Line: item.line, Msg: fmt.Sprintf("duplicate node id %v", id)}
+			}
+			delete(attrs, "id")
+			graph.AddVertex(id)
+			graph.VertexAttributes[id] = attrs
+		case item.key == "edge" && isList:
+			edges = append(edges, item)
+		case item.key == "directed":
+		default:
+			graph.Attributes[item.key] = attributes([]pair{item})[item.key]
+		}
+	}
+
+	// Edges are added after the nodes, since they may refer to nodes
+	// that come later in the input.
+	for _, item := range edges {
+		attrs := attributes(item.value.([]pair))
+		source, hasSource := attrs["source"]
+		target, hasTarget := attrs["target"]
+		if !hasSource || !hasTarget {
+			return nil, &SyntaxError{Line: item.line, Msg: "edge without source or target"}
+		}
+		for _, vertex := range []interface{}{source, target} {
+			if !graph.HasVertex(vertex) {
+				return nil, &SyntaxError{Line: item.line, Msg: fmt.Sprintf("edge refers to unknown node %v", vertex)}
+			}
+		}
+		delete(attrs, "source")
+		delete(attrs, "target")
+		graph.AddEdge(source, target)
+		graph.EdgeAttributes[directed.Edge{Source: source, Target: target}] = attrs
+		if !graph.Directed {
+			graph.AddEdge(target, source)
+			graph.EdgeAttributes[directed.Edge{Source: target, Target: source}] = attrs
+		}
+	}
+	return graph, nil
+}
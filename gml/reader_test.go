@@ -0,0 +1,179 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package gml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// readFile will read a graph from a file in the testdata directory.
+func readFile(t *testing.T, name string) *Graph {
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer file.Close()
+	graph, err := Read(file)
+	if err != nil {
+		t.Fatalf("Read of %s failed: %v", name, err)
+	}
+	return graph
+}
+
+func TestReadSpec(t *testing.T) {
+	graph := readFile(t, "spec.gml")
+	if !graph.Directed || graph.Order() != 3 || graph.Size() != 3 {
+		t.Errorf("Wrong graph with %d vertices and %d edges", graph.Order(), graph.Size())
+	}
+	if !graph.HasEdge(1, 2) || !graph.HasEdge(3, 1) || graph.HasEdge(2, 1) {
+		t.Errorf("Wrong edges")
+	}
+	expected := Attributes{"comment": "This is a sample graph", "id": 42, "label": "Hello, I am a graph"}
+	if !reflect.DeepEqual(graph.Attributes, expected) {
+		t.Errorf("Expected graph attributes %v, got %v", expected, graph.Attributes)
+	}
+	expected = Attributes{"label": "node 2", "thisIsASampleAttribute": 43}
+	if !reflect.DeepEqual(graph.VertexAttributes[2], expected) {
+		t.Errorf("Expected vertex attributes %v, got %v", expected, graph.VertexAttributes[2])
+	}
+	if label := graph.EdgeAttributes[directed.Edge{Source: 3, Target: 1}]["label"]; label != "Edge from node 3 to node 1" {
+		t.Errorf("Wrong edge label %v", label)
+	}
+}
+
+func TestReadNetworkX(t *testing.T) {
+	graph := readFile(t, "networkx.gml")
+	if graph.Directed || graph.Order() != 3 || graph.Size() != 4 {
+		t.Errorf("Wrong graph with %d vertices and %d edges", graph.Order(), graph.Size())
+	}
+	if !graph.HasEdge(1, 0) || !graph.HasEdge(2, 1) {
+		t.Errorf("Undirected edges not added in both directions")
+	}
+	if color := graph.VertexAttributes[1]["color"]; color != `"blue"` {
+		t.Errorf("Wrong color %v", color)
+	}
+	expected := Attributes{
+		"label":    "café",
+		"size":     2.5,
+		"graphics": Attributes{"x": 10.0, "y": -4.5},
+	}
+	if !reflect.DeepEqual(graph.VertexAttributes[2], expected) {
+		t.Errorf("Expected vertex attributes %v, got %v", expected, graph.VertexAttributes[2])
+	}
+	if w := graph.EdgeAttributes[directed.Edge{Source: 2, Target: 1}]["weight"]; w != math.Inf(1) {
+		t.Errorf("Expected infinite weight, got %v", w)
+	}
+}
+
+func TestReadIgraph(t *testing.T) {
+	graph := readFile(t, "igraph.gml")
+	if !graph.Directed || graph.Order() != 3 || graph.Size() != 3 {
+		t.Errorf("Wrong graph with %d vertices and %d edges", graph.Order(), graph.Size())
+	}
+	weights := []interface{}{
+		graph.EdgeAttributes[directed.Edge{Source: 0, Target: 1}]["weight"],
+		graph.EdgeAttributes[directed.Edge{Source: 1, Target: 2}]["weight"],
+		graph.EdgeAttributes[directed.Edge{Source: 2, Target: 0}]["weight"],
+	}
+	if expected := []interface{}{2, 0.25, -1e-05}; !reflect.DeepEqual(weights, expected) {
+		t.Errorf("Expected weights %v, got %v", expected, weights)
+	}
+}
+
+// TestConformance will check that each sample file is unchanged by
+// writing it and reading it back.
+func TestConformance(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("testdata", "*.gml"))
+	if err != nil || len(names) == 0 {
+		t.Fatalf("No sample files: %v", err)
+	}
+	for _, name := range names {
+		graph := readFile(t, filepath.Base(name))
+		var buf bytes.Buffer
+		if err := graph.Writer().Write(&buf, graph.Graph); err != nil {
+			t.Errorf("Write of %s failed: %v", name, err)
+			continue
+		}
+		result, err := Read(&buf)
+		if err != nil {
+			t.Errorf("Read of written %s failed: %v", name, err)
+			continue
+		}
+		if result.Directed != graph.Directed {
+			t.Errorf("%s: expected directed %v, got %v", name, graph.Directed, result.Directed)
+		}
+		if result.Order() != graph.Order() || result.Size() != graph.Size() {
+			t.Errorf("%s: expected %d vertices and %d edges, got %d and %d", name,
+				graph.Order(), graph.Size(), result.Order(), result.Size())
+		}
+		if !reflect.DeepEqual(result.Attributes, graph.Attributes) {
+			t.Errorf("%s: expected graph attributes %v, got %v", name, graph.Attributes, result.Attributes)
+		}
+		if !reflect.DeepEqual(result.VertexAttributes, graph.VertexAttributes) {
+			t.Errorf("%s: vertex attributes differ", name)
+		}
+		if !reflect.DeepEqual(result.EdgeAttributes, graph.EdgeAttributes) {
+			t.Errorf("%s: edge attributes differ", name)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	inputs := []string{
+		``,
+		`Creator "me"`,
+		`graph [ node [ label "x" ] ]`,
+		`graph [ node [ id 1 ] node [ id 1 ] ]`,
+		`graph [ node [ id 1 ] edge [ source 1 ] ]`,
+		`graph [ node [ id 1 ] edge [ source 1 target 2 ] ]`,
+		`graph [ node [ id 1 ]`,
+		`graph [ label "unterminated ]`,
+		`graph [ id 1.2.3 ]`,
+		`graph [ 1 2 ]`,
+		`graph [ id ] ]`,
+		`graph ] `,
+		`graph [ id @ ]`,
+	}
+	for _, input := range inputs {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+
+	_, err := Read(strings.NewReader("graph [\n  # comment\n  node [ id 1 ]\n  node [ id x ]\n]"))
+	if err, ok := err.(*SyntaxError); !ok || err.Line != 4 {
+		t.Errorf("Expected syntax error on line 4, got %v", err)
+	}
+}
+
+func TestSerializeErrors(t *testing.T) {
+	graph, err := Read(strings.NewReader(`graph [ node [ id 1 ] node [ id 2 ] edge [ source 1 target 2 ] ]`))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if _, err := json.Marshal(graph); !errors.Is(err, ErrAttributes) {
+		t.Errorf("Expected ErrAttributes from json.Marshal, got %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := graph.WriteTo(&buf); !errors.Is(err, ErrAttributes) {
+		t.Errorf("Expected ErrAttributes from WriteTo, got %v", err)
+	}
+
+	// The embedded graph can still be used to save the structure.
+	if _, err := graph.WithCodec(directed.IntCodec{}).WriteTo(&buf); err != nil {
+		t.Errorf("WriteTo failed: %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+Creator "igraph version 0.10.4 Mon Jan 16 09:12:41 2023"
+Version 1
+graph
+[
+  directed 1
+  node
+  [
+    id 0
+    name "a"
+  ]
+  node
+  [
+    id 1
+    name "b"
+  ]
+  node
+  [
+    id 2
+    name "c"
+  ]
+  edge
+  [
+    source 0
+    target 1
+    weight 2
+  ]
+  edge
+  [
+    source 1
+    target 2
+    weight 0.25
+  ]
+  edge
+  [
+    source 2
+    target 0
+    weight -1e-05
+  ]
+]
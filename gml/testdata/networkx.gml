@@ -0,0 +1,32 @@
+graph [
+  name "path_graph(3)"
+  node [
+    id 0
+    label "0"
+    color "red"
+  ]
+  node [
+    id 1
+    label "1"
+    color "&#34;blue&#34;"
+  ]
+  node [
+    id 2
+    label "caf&#233;"
+    size 2.5
+    graphics [
+      x 10.0
+      y -4.5
+    ]
+  ]
+  edge [
+    source 0
+    target 1
+    weight 1.5
+  ]
+  edge [
+    source 1
+    target 2
+    weight INF
+  ]
+]
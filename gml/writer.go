@@ -0,0 +1,220 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package gml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Writer writes directed graphs in GML format. The zero value writes
+// a graph where the nodes are numbered in the order the vertices were
+// added and labelled with the string representation of each vertex.
+type Writer struct {
+	// Attributes of the graph as a whole.
+	Attributes Attributes
+
+	// Undirected will, if true, write the graph as undirected. Each
+	// pair of edges in opposite directions is then written as a
+	// single edge, with the attributes of the edge written first.
+	Undirected bool
+
+	// VertexID is called to get the ID of each vertex. The IDs
+	// have to be unique. If nil, the vertices are numbered from
+	// zero and each node gets a "label" key with the string
+	// representation of the vertex, unless the attributes of the
+	// vertex have one.
+	VertexID func(vertex directed.Vertex) int
+
+	// VertexAttributes is called to get the attributes of each
+	// vertex.
+	VertexAttributes func(vertex directed.Vertex) Attributes
+
+	// EdgeAttributes is called to get the attributes of each edge.
+	EdgeAttributes func(source, target directed.Vertex) Attributes
+}
+
+// validKey will check that 'key' can be written as a GML key.
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// quote will return 's' as a GML string. Since strings cannot contain
+// double quotes, they are written as HTML character entities, the same
+// way NetworkX does.
+func quote(s string) string {
+	s = strings.Replace(s, "&", "&amp;", -1)
+	s = strings.Replace(s, `"`, "&quot;", -1)
+	return `"` + s + `"`
+}
+
+// formatReal will format a real number so that it is read back as a
+// real number and not as an integer.
+func formatReal(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	case math.IsNaN(f):
+		return "NAN"
+	}
+	text := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(text, ".eE") {
+		text += ".0"
+	}
+	return text
+}
+
+// gmlWriter keeps track of the output and the first error.
+type gmlWriter struct {
+	buf *bufio.Writer
+	err error
+}
+
+// attributes will write the attributes with the given indentation,
+// with "label" first and the other keys in sorted order. The keys in
+// 'skip' are not written.
+func (w *gmlWriter) attributes(indent string, attrs Attributes, skip ...string) {
+	var keys []string
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == "label") != (keys[j] == "label") {
+			return keys[i] == "label"
+		}
+		return keys[i] < keys[j]
+	})
+next:
+	for _, key := range keys {
+		for _, name := range skip {
+			if key == name {
+				continue next
+			}
+		}
+		w.value(indent, key, attrs[key])
+	}
+}
+
+// value will write a single key and value.
+func (w *gmlWriter) value(indent, key string, value interface{}) {
+	if w.err != nil {
+		return
+	}
+	if !validKey(key) {
+		w.err = fmt.Errorf("gml: invalid key %q", key)
+		return
+	}
+	switch v := value.(type) {
+	case int:
+		fmt.Fprintf(w.buf, "%s%s %d\n", indent, key, v)
+	case int64:
+		fmt.Fprintf(w.buf, "%s%s %d\n", indent, key, v)
+	case float32:
+		fmt.Fprintf(w.buf, "%s%s %s\n", indent, key, formatReal(float64(v)))
+	case float64:
+		fmt.Fprintf(w.buf, "%s%s %s\n", indent, key, formatReal(v))
+	case string:
+		fmt.Fprintf(w.buf, "%s%s %s\n", indent, key, quote(v))
+	case Attributes:
+		fmt.Fprintf(w.buf, "%s%s [\n", indent, key)
+		w.attributes(indent+"  ", v)
+		fmt.Fprintf(w.buf, "%s]\n", indent)
+	default:
+		w.err = fmt.Errorf("gml: unsupported type %T of key %q", value, key)
+	}
+}
+
+// Write will write the graph to 'out' in GML format.
+func (w *Writer) Write(out io.Writer, graph *directed.Graph) error {
+	ids := make(map[directed.Vertex]int, graph.Order())
+	used := make(map[int]directed.Vertex, graph.Order())
+	err := graph.DoVertices(func(vertex directed.Vertex) error {
+		id := len(ids)
+		if w.VertexID != nil {
+			id = w.VertexID(vertex)
+		}
+		if other, ok := used[id]; ok {
+			return fmt.Errorf("gml: vertices %v and %v have the same ID %d", other, vertex, id)
+		}
+		ids[vertex], used[id] = id, vertex
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	gw := &gmlWriter{buf: bufio.NewWriter(out)}
+	gw.buf.WriteString("graph [\n")
+	if w.Undirected {
+		gw.buf.WriteString("  directed 0\n")
+	} else {
+		gw.buf.WriteString("  directed 1\n")
+	}
+	gw.attributes("  ", w.Attributes, "directed", "node", "edge")
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		var attrs Attributes
+		if w.VertexAttributes != nil {
+			attrs = w.VertexAttributes(vertex)
+		}
+		gw.buf.WriteString("  node [\n")
+		fmt.Fprintf(gw.buf, "    id %d\n", ids[vertex])
+		if _, ok := attrs["label"]; !ok && w.VertexID == nil {
+			fmt.Fprintf(gw.buf, "    label %s\n", quote(fmt.Sprint(vertex)))
+		}
+		gw.attributes("    ", attrs, "id")
+		gw.buf.WriteString("  ]\n")
+		return gw.err
+	})
+	written := make(map[directed.Edge]bool)
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		if w.Undirected {
+			if written[directed.Edge{Source: target, Target: source}] {
+				return nil
+			}
+			written[directed.Edge{Source: source, Target: target}] = true
+		}
+		gw.buf.WriteString("  edge [\n")
+		fmt.Fprintf(gw.buf, "    source %d\n", ids[source])
+		fmt.Fprintf(gw.buf, "    target %d\n", ids[target])
+		if w.EdgeAttributes != nil {
+			gw.attributes("    ", w.EdgeAttributes(source, target), "source", "target")
+		}
+		gw.buf.WriteString("  ]\n")
+		return gw.err
+	})
+	gw.buf.WriteString("]\n")
+	if gw.err != nil {
+		return gw.err
+	}
+	return gw.buf.Flush()
+}
+
+// Write will write the graph to 'out' in GML format using the default
+// writer.
+func Write(out io.Writer, graph *directed.Graph) error {
+	var w Writer
+	return w.Write(out, graph)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package gml
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func TestWrite(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", `say "hi" & go`)
+
+	w := Writer{
+		Attributes: Attributes{"name": "Example"},
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			if vertex == "a" {
+				return Attributes{"size": 3, "graphics": Attributes{"x": 1.0, "y": 2.5}}
+			}
+			return nil
+		},
+		EdgeAttributes: func(source, target directed.Vertex) Attributes {
+			if source == "a" {
+				return Attributes{"weight": 2.0, "label": "first"}
+			}
+			return Attributes{"weight": math.Inf(-1)}
+		},
+	}
+	var out bytes.Buffer
+	if err := w.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expected := `graph [
+  directed 1
+  name "Example"
+  node [
+    id 0
+    label "a"
+    graphics [
+      x 1.0
+      y 2.5
+    ]
+    size 3
+  ]
+  node [
+    id 1
+    label "b"
+  ]
+  node [
+    id 2
+    label "say &quot;hi&quot; &amp; go"
+  ]
+  edge [
+    source 0
+    target 1
+    label "first"
+    weight 2.0
+  ]
+  edge [
+    source 1
+    target 2
+    weight -INF
+  ]
+]
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	result, err := Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if label := result.VertexAttributes[2]["label"]; label != `say "hi" & go` {
+		t.Errorf("Wrong label %q", label)
+	}
+}
+
+func TestWriterOfRead(t *testing.T) {
+	input := `graph [
+  node [ id "a" ]
+  node [ id "b" label "Bee" ]
+  node [ id 3.5 ]
+  edge [ source "a" target "b" weight 2 ]
+  edge [ source "b" target 3.5 ]
+]`
+	graph, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	var out bytes.Buffer
+	if err := graph.Writer().Write(&out, graph.Graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expected := `graph [
+  directed 0
+  node [
+    id 0
+    label "a"
+  ]
+  node [
+    id 1
+    label "Bee"
+  ]
+  node [
+    id 2
+    label "3.5"
+  ]
+  edge [
+    source 0
+    target 1
+    weight 2
+  ]
+  edge [
+    source 1
+    target 2
+  ]
+]
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	result, err := Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if result.Directed || result.Size() != graph.Size() || !result.HasEdge(1, 0) {
+		t.Errorf("Expected undirected graph with %d edges, got %d", graph.Size(), result.Size())
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge(1, 2)
+	var out bytes.Buffer
+	w := Writer{VertexID: func(vertex directed.Vertex) int { return 7 }}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for duplicate IDs")
+	}
+
+	w = Writer{Attributes: Attributes{"bad key": 1}}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for invalid key")
+	}
+
+	w = Writer{
+		EdgeAttributes: func(source, target directed.Vertex) Attributes {
+			return Attributes{"flag": true}
+		},
+	}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for unsupported type")
+	}
+}
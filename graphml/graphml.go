@@ -0,0 +1,268 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package graphml implements reading and writing of graphs in the
+// GraphML format, which is used by tools such as Gephi and yEd.
+package graphml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Namespace is the XML namespace of GraphML documents.
+const Namespace = "http://graphml.graphdrawing.org/xmlns"
+
+// Attributes are the data values of a graph, vertex, or edge, keyed
+// by attribute name. The values have one of the types bool, int,
+// int64, float32, float64, or string, corresponding to the GraphML
+// types boolean, int, long, float, double, and string. Since the
+// GraphML int is 32 bits, values of type int that do not fit in 32
+// bits are written as long, and read back as int64.
+type Attributes map[string]interface{}
+
+// Key is the declaration of an attribute.
+type Key struct {
+	// ID of the key in the document.
+	ID string
+
+	// For is the kind of element the attribute is for: "graph",
+	// "node", "edge", or "all".
+	For string
+
+	// Name of the attribute.
+	Name string
+
+	// Type of the attribute: "boolean", "int", "long", "float",
+	// "double", or "string".
+	Type string
+
+	// Default value of the attribute, or nil if there is no
+	// default.
+	Default interface{}
+}
+
+// Graph is a graph read from GraphML together with the attribute
+// declarations and the attributes of the graph, its vertices, and its
+// edges. The vertices are the node IDs used in the document.
+type Graph struct {
+	*directed.Graph
+
+	// ID of the graph, or an empty string if it had no ID.
+	ID string
+
+	// Directed is false if the default of the edges was undirected,
+	// in which case each undirected edge was added in both
+	// directions.
+	Directed bool
+
+	// Keys are the attribute declarations, in document order.
+	Keys []Key
+
+	// Attributes of the graph as a whole.
+	Attributes Attributes
+
+	// VertexAttributes are the attributes of each vertex.
+	VertexAttributes map[directed.Vertex]Attributes
+
+	// EdgeAttributes are the attributes of each edge.
+	EdgeAttributes map[directed.Edge]Attributes
+}
+
+// ErrAttributes is returned when trying to save or load the graph
+// using the JSON Graph Format or a binary snapshot, which cannot hold
+// the keys and attributes. The methods of the embedded graph can be used
+// to save or load only the vertices and edges.
+var ErrAttributes = errors.New("graphml: graph has attributes that cannot be saved, use Writer")
+
+// MarshalJSON will return ErrAttributes.
+func (graph *Graph) MarshalJSON() ([]byte, error) {
+	return nil, ErrAttributes
+}
+
+// UnmarshalJSON will return ErrAttributes.
+func (graph *Graph) UnmarshalJSON(data []byte) error {
+	return ErrAttributes
+}
+
+// WriteTo will return ErrAttributes.
+func (graph *Graph) WriteTo(out io.Writer) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// WriteWeightedTo will return ErrAttributes.
+func (graph *Graph) WriteWeightedTo(out io.Writer, weight directed.WeightFunc) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// ReadFrom will return ErrAttributes.
+func (graph *Graph) ReadFrom(in io.Reader) (int64, error) {
+	return 0, ErrAttributes
+}
+
+// ReadWeightedFrom will return ErrAttributes.
+func (graph *Graph) ReadWeightedFrom(in io.Reader) (directed.Weights, int64, error) {
+	return nil, 0, ErrAttributes
+}
+
+// Weight will return the "weight" attribute of the edge from 'source'
+// to 'target' as a float64. If the edge has no weight, the default of
+// the "weight" key is used, or 1 if there is no default. The method
+// can be used as a directed.WeightFunc.
+func (graph *Graph) Weight(source, target directed.Vertex) float64 {
+	value, ok := graph.EdgeAttributes[directed.Edge{Source: source, Target: target}]["weight"]
+	if !ok {
+		for _, key := range graph.Keys {
+			if key.Name == "weight" && (key.For == "edge" || key.For == "all") {
+				value = key.Default
+				break
+			}
+		}
+	}
+	switch value := value.(type) {
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case float32:
+		return float64(value)
+	case float64:
+		return value
+	}
+	return 1
+}
+
+// Writer will return a writer that writes the graph with the keys and
+// attributes that were read, as a directed or undirected graph
+// depending on how it was read.
+func (graph *Graph) Writer() *Writer {
+	return &Writer{
+		ID:         graph.ID,
+		Undirected: !graph.Directed,
+		Keys:       graph.Keys,
+		Attributes: graph.Attributes,
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			return graph.VertexAttributes[vertex]
+		},
+		EdgeAttributes: func(source, target directed.Vertex) Attributes {
+			return graph.EdgeAttributes[directed.Edge{Source: source, Target: target}]
+		},
+	}
+}
+
+// typeOf will return the GraphML type for a value, or an empty string
+// if the value does not have a GraphML type.
+func typeOf(value interface{}) string {
+	switch value := value.(type) {
+	case bool:
+		return "boolean"
+	case int:
+		if value < math.MinInt32 || value > math.MaxInt32 {
+			return "long"
+		}
+		return "int"
+	case int64:
+		return "long"
+	case float32:
+		return "float"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	}
+	return ""
+}
+
+// knownType will check if 'kind' is a GraphML type. An empty type is
+// treated as a string.
+func knownType(kind string) bool {
+	switch kind {
+	case "boolean", "int", "long", "float", "double", "string", "":
+		return true
+	}
+	return false
+}
+
+// format will return the text representation of a value.
+func format(value interface{}) string {
+	switch value := value.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(value), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// parse will parse the text representation of a value of the given
+// GraphML type.
+func parse(kind, text string) (interface{}, error) {
+	switch kind {
+	case "boolean":
+		return strconv.ParseBool(text)
+	case "int":
+		value, err := strconv.ParseInt(text, 10, 32)
+		return int(value), err
+	case "long":
+		return strconv.ParseInt(text, 10, 64)
+	case "float":
+		value, err := strconv.ParseFloat(text, 32)
+		return float32(value), err
+	case "double":
+		return strconv.ParseFloat(text, 64)
+	}
+	return text, nil
+}
+
+// The XML structure of a GraphML document. Only the parts used by the
+// package are included, so nested graphs, hyperedges, and ports are
+// ignored when reading.
+
+type xmlDocument struct {
+	XMLName xml.Name   `xml:"graphml"`
+	Xmlns   string     `xml:"xmlns,attr,omitempty"`
+	Keys    []xmlKey   `xml:"key"`
+	Graphs  []xmlGraph `xml:"graph"`
+}
+
+type xmlKey struct {
+	ID      string  `xml:"id,attr"`
+	For     string  `xml:"for,attr,omitempty"`
+	Name    string  `xml:"attr.name,attr,omitempty"`
+	Type    string  `xml:"attr.type,attr,omitempty"`
+	Default *string `xml:"default"`
+}
+
+type xmlGraph struct {
+	ID          string    `xml:"id,attr,omitempty"`
+	EdgeDefault string    `xml:"edgedefault,attr"`
+	Data        []xmlData `xml:"data"`
+	Nodes       []xmlNode `xml:"node"`
+	Edges       []xmlEdge `xml:"edge"`
+}
+
+type xmlNode struct {
+	ID   string    `xml:"id,attr"`
+	Data []xmlData `xml:"data"`
+}
+
+type xmlEdge struct {
+	ID       string    `xml:"id,attr,omitempty"`
+	Directed string    `xml:"directed,attr,omitempty"`
+	Source   string    `xml:"source,attr"`
+	Target   string    `xml:"target,attr"`
+	Data     []xmlData `xml:"data"`
+}
+
+type xmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// reader keeps track of the keys while reading a document.
+type reader struct {
+	keys map[string]*Key
+}
+
+// attributes will convert the data elements of a graph, node, or edge
+// to attributes using the type of each key.
+func (r *reader) attributes(data []xmlData) (Attributes, error) {
+	attrs := Attributes{}
+	for _, d := range data {
+		key, ok := r.keys[d.Key]
+		if !ok {
+			return nil, fmt.Errorf("graphml: data for undeclared key %q", d.Key)
+		}
+		text := d.Value
+		if key.Type != "string" && key.Type != "" {
+			text = strings.TrimSpace(text)
+		}
+		value, err := parse(key.Type, text)
+		if err != nil {
+			return nil, fmt.Errorf("graphml: bad value %q for key %q: %v", d.Value, d.Key, err)
+		}
+		attrs[key.Name] = value
+	}
+	return attrs, nil
+}
+
+// Read will read a graph in GraphML format from 'in'. Only the first
+// graph of the document is read. If the graph is undirected, each edge
+// is added in both directions. Nested graphs, hyperedges, ports, and
+// data that is not plain text, such as the graphics of yEd, are
+// ignored.
+//
+// The attribute values are converted to Go values according to the
+// type of the key. Default values are recorded in the keys, but not
+// added to the attributes of each vertex and edge.
+func Read(in io.Reader) (*Graph, error) {
+	var doc xmlDocument
+	if err := xml.NewDecoder(in).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Graphs) == 0 {
+		return nil, fmt.Errorf("graphml: no graph in document")
+	}
+
+	graph := &Graph{
+		Graph:            directed.New(),
+		VertexAttributes: make(map[directed.Vertex]Attributes),
+		EdgeAttributes:   make(map[directed.Edge]Attributes),
+	}
+	r := &reader{keys: make(map[string]*Key)}
+	for _, xk := range doc.Keys {
+		key := Key{ID: xk.ID, For: xk.For, Name: xk.Name, Type: xk.Type}
+		if key.For == "" {
+			key.For = "all"
+		}
+		if key.Name == "" {
+			key.Name = key.ID
+		}
+		if !knownType(key.Type) {
+			return nil, fmt.Errorf("graphml: unknown type %q of key %q", key.Type, key.ID)
+		}
+		if xk.Default != nil {
+			value, err := parse(key.Type, strings.TrimSpace(*xk.Default))
+			if err != nil {
+				return nil, fmt.Errorf("graphml: bad default for key %q: %v", key.ID, err)
+			}
+			key.Default = value
+		}
+		graph.Keys = append(graph.Keys, key)
+	}
+	for i := range graph.Keys {
+		r.keys[graph.Keys[i].ID] = &graph.Keys[i]
+	}
+
+	xg := doc.Graphs[0]
+	graph.ID = xg.ID
+	graph.Directed = xg.EdgeDefault != "undirected"
+	attrs, err := r.attributes(xg.Data)
+	if err != nil {
+		return nil, err
+	}
+	graph.Attributes = attrs
+
+	for _, node := range xg.Nodes {
+		attrs, err := r.attributes(node.Data)
+		if err != nil {
+			return nil, err
+		}
+		graph.AddVertex(node.ID)
+		graph.VertexAttributes[node.ID] = attrs
+	}
+
+	for _, edge := range xg.Edges {
+		attrs, err := r.attributes(edge.Data)
+		if err != nil {
+			return nil, err
+		}
+		isDirected := graph.Directed
+		if edge.Directed != "" {
+			isDirected, err = strconv.ParseBool(edge.Directed)
+			if err != nil {
+				return nil, fmt.Errorf("graphml: bad directed attribute %q", edge.Directed)
+			}
+		}
+		for _, vertex := range []string{edge.Source, edge.Target} {
+			if _, ok := graph.VertexAttributes[vertex]; !ok {
+				graph.AddVertex(vertex)
+				graph.VertexAttributes[vertex] = Attributes{}
+			}
+		}
+		graph.AddEdge(edge.Source, edge.Target)
+		graph.EdgeAttributes[directed.Edge{Source: edge.Source, Target: edge.Target}] = attrs
+		if !isDirected {
+			graph.AddEdge(edge.Target, edge.Source)
+			graph.EdgeAttributes[directed.Edge{Source: edge.Target, Target: edge.Source}] = attrs
+		}
+	}
+	return graph, nil
+}
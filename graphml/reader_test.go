@@ -0,0 +1,144 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package graphml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func TestRead(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns"
+         xmlns:y="http://www.yworks.com/xml/graphml">
+  <key id="d0" for="node" attr.name="label" attr.type="string"/>
+  <key id="d1" for="edge" attr.name="weight" attr.type="double">
+    <default>2.0</default>
+  </key>
+  <key id="d2" for="node" yfiles.type="nodegraphics"/>
+  <graph id="G" edgedefault="undirected">
+    <node id="n0">
+      <data key="d0">Start</data>
+      <data key="d2"><y:ShapeNode><y:Shape type="box"/></y:ShapeNode></data>
+    </node>
+    <node id="n1"/>
+    <edge source="n0" target="n1">
+      <data key="d1"> 0.5 </data>
+    </edge>
+    <edge source="n1" target="n2" directed="true"/>
+  </graph>
+</graphml>
+`
+	graph, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if graph.ID != "G" || graph.Directed || graph.Order() != 3 || graph.Size() != 3 {
+		t.Errorf("Wrong graph %q with %d vertices and %d edges",
+			graph.ID, graph.Order(), graph.Size())
+	}
+	if !graph.HasEdge("n1", "n0") || graph.HasEdge("n2", "n1") {
+		t.Errorf("Wrong edge directions")
+	}
+	if label := graph.VertexAttributes["n0"]["label"]; label != "Start" {
+		t.Errorf("Wrong label %v", label)
+	}
+	if len(graph.Keys) != 3 || graph.Keys[1].Default != 2.0 || graph.Keys[2].Name != "d2" {
+		t.Errorf("Wrong keys: %+v", graph.Keys)
+	}
+	if w := graph.Weight("n0", "n1"); w != 0.5 {
+		t.Errorf("Expected weight 0.5, got %v", w)
+	}
+	if w := graph.Weight("n1", "n2"); w != 2.0 {
+		t.Errorf("Expected default weight 2, got %v", w)
+	}
+
+	// The weights can be used with the algorithms of the directed
+	// package.
+	tree, total := graph.PrimSpanningForest(graph.Weight)
+	if tree.Size() != 2 || total != 2.5 {
+		t.Errorf("Expected spanning forest with weight 2.5, got %v", total)
+	}
+
+	// Writing the graph back keeps it undirected, with the edge
+	// that was directed written as such.
+	var out bytes.Buffer
+	if err := graph.Writer().Write(&out, graph.Graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `edgedefault="undirected"`) ||
+		!strings.Contains(out.String(), `<edge directed="true" source="n1" target="n2">`) ||
+		strings.Count(out.String(), "<edge ") != 2 {
+		t.Errorf("Wrong undirected graph written:\n%s", out.String())
+	}
+	result, err := Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if result.Directed || result.Size() != graph.Size() || !result.HasEdge("n1", "n0") || result.HasEdge("n2", "n1") {
+		t.Errorf("Wrong graph read back with %d edges", result.Size())
+	}
+	if !reflect.DeepEqual(result.EdgeAttributes, graph.EdgeAttributes) {
+		t.Errorf("Expected edge attributes %v, got %v", graph.EdgeAttributes, result.EdgeAttributes)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []string{
+		`<graphml>`,
+		`<graphml></graphml>`,
+		`<graphml><graph><node id="a"><data key="x">1</data></node></graph></graphml>`,
+		`<graphml><key id="k" attr.type="int"/><graph><node id="a"><data key="k">one</data></node></graph></graphml>`,
+		`<graphml><key id="k" attr.type="complex"/><graph/></graphml>`,
+		`<graphml><graph><edge source="a" target="b" directed="maybe"/></graph></graphml>`,
+	}
+	for _, input := range tests {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
+
+func ExampleRead() {
+	input := `<graphml>
+  <graph edgedefault="directed">
+    <node id="a"/><node id="b"/>
+    <edge source="a" target="b"/>
+  </graph>
+</graphml>`
+	graph, _ := Read(strings.NewReader(input))
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		fmt.Println(source, "->", target)
+		return nil
+	})
+	// Output: a -> b
+}
+
+func TestSerializeErrors(t *testing.T) {
+	graph, err := Read(strings.NewReader(`<graphml><graph><node id="a"/><node id="b"/><edge source="a" target="b"/></graph></graphml>`))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if _, err := json.Marshal(graph); !errors.Is(err, ErrAttributes) {
+		t.Errorf("Expected ErrAttributes from json.Marshal, got %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := graph.WriteTo(&buf); !errors.Is(err, ErrAttributes) {
+		t.Errorf("Expected ErrAttributes from WriteTo, got %v", err)
+	}
+
+	// The embedded graph can still be used to save the structure.
+	if _, err := graph.Graph.WriteTo(&buf); err != nil {
+		t.Errorf("WriteTo failed: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Writer writes directed graphs in GraphML format. The zero value
+// writes a graph using the string representation of each vertex as
+// its ID and without any attributes.
+//
+// Keys for the attributes are declared automatically using the type
+// of the values, so values read back have the same types as the
+// values written. The exception is values of type int, where the key
+// is declared as long if any of the values does not fit in 32 bits,
+// so that all the values of the attribute are read back as int64.
+type Writer struct {
+	// ID of the graph. If empty, the graph has no ID.
+	ID string
+
+	// Keys to declare in addition to the keys declared
+	// automatically. This can be used to give a key a default
+	// value or a specific ID.
+	Keys []Key
+
+	// Attributes of the graph as a whole.
+	Attributes Attributes
+
+	// Undirected will, if true, write the graph with undirected
+	// edges as default. Each pair of edges in opposite directions
+	// is then written as a single edge, with the attributes of the
+	// edge written first, while edges without a reverse edge are
+	// written as directed edges.
+	Undirected bool
+
+	// VertexID is called to get the ID of each vertex. The IDs
+	// have to be unique. If nil, the string representation of the
+	// vertex is used.
+	VertexID func(vertex directed.Vertex) string
+
+	// VertexAttributes is called to get the attributes of each
+	// vertex.
+	VertexAttributes func(vertex directed.Vertex) Attributes
+
+	// EdgeAttributes is called to get the attributes of each edge.
+	EdgeAttributes func(source, target directed.Vertex) Attributes
+
+	// Weight is called, if set, to get the weight of each edge,
+	// which is written as the "weight" attribute of the edge.
+	Weight directed.WeightFunc
+}
+
+// keySet keeps track of the keys declared while writing a document.
+type keySet struct {
+	keys  []xmlKey
+	types map[string]string
+	ids   map[[2]string]string
+
+	// auto are the keys declared automatically, which can be
+	// changed from int to long.
+	auto map[string]bool
+}
+
+// declare will add a key declaration, checking that the ID is unique.
+func (ks *keySet) declare(key Key) error {
+	if _, ok := ks.types[key.ID]; ok {
+		return fmt.Errorf("graphml: duplicate key ID %q", key.ID)
+	}
+	kind := key.Type
+	if kind == "" {
+		kind = "string"
+	}
+	if key.For == "" {
+		key.For = "all"
+	}
+	xk := xmlKey{ID: key.ID, For: key.For, Name: key.Name, Type: kind}
+	if key.Default != nil {
+		if typeOf(key.Default) != kind {
+			return fmt.Errorf("graphml: default %#v does not match type %q of key %q",
+				key.Default, kind, key.ID)
+		}
+		text := format(key.Default)
+		xk.Default = &text
+	}
+	ks.keys = append(ks.keys, xk)
+	ks.types[key.ID] = kind
+	ks.ids[[2]string{key.For, key.Name}] = key.ID
+	return nil
+}
+
+// widen will change the type of a key from int to long.
+func (ks *keySet) widen(id string) {
+	ks.types[id] = "long"
+	for i := range ks.keys {
+		if ks.keys[i].ID == id {
+			ks.keys[i].Type = "long"
+		}
+	}
+}
+
+// newID will return an unused key ID.
+func (ks *keySet) newID() string {
+	for n := len(ks.keys); ; n++ {
+		id := fmt.Sprintf("d%d", n)
+		if _, taken := ks.types[id]; !taken {
+			return id
+		}
+	}
+}
+
+// data will convert attributes for the given kind of element to data
+// elements, declaring new keys as necessary. The data elements are
+// sorted by attribute name so that the output is deterministic.
+func (ks *keySet) data(domain string, attrs Attributes) ([]xmlData, error) {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var data []xmlData
+	for _, name := range names {
+		value := attrs[name]
+		kind := typeOf(value)
+		if kind == "" {
+			return nil, fmt.Errorf("graphml: unsupported type %T of attribute %q", value, name)
+		}
+		id, ok := ks.ids[[2]string{domain, name}]
+		if !ok {
+			id, ok = ks.ids[[2]string{"all", name}]
+		}
+		if !ok {
+			id = ks.newID()
+			if err := ks.declare(Key{ID: id, For: domain, Name: name, Type: kind}); err != nil {
+				return nil, err
+			}
+			ks.auto[id] = true
+		}
+		if _, ok := value.(int); ok && ks.types[id] != kind {
+			switch {
+			case ks.types[id] == "long":
+				kind = "long"
+			case ks.types[id] == "int" && ks.auto[id]:
+				ks.widen(id)
+				kind = "long"
+			}
+		}
+		if ks.types[id] != kind {
+			return nil, fmt.Errorf("graphml: attribute %q of type %T does not match type %q of key %q",
+				name, value, ks.types[id], id)
+		}
+		data = append(data, xmlData{Key: id, Value: format(value)})
+	}
+	return data, nil
+}
+
+// Write will write the graph to 'out' in GraphML format. The vertices
+// are written in the order they were added to the graph, followed by
+// the edges. An error is returned if two vertices have the same ID,
+// if an attribute value does not have a GraphML type, or if writing
+// fails.
+func (w *Writer) Write(out io.Writer, graph *directed.Graph) error {
+	ks := &keySet{
+		types: make(map[string]string),
+		ids:   make(map[[2]string]string),
+		auto:  make(map[string]bool),
+	}
+	for _, key := range w.Keys {
+		if err := ks.declare(key); err != nil {
+			return err
+		}
+	}
+
+	doc := xmlDocument{Xmlns: Namespace}
+	xg := xmlGraph{ID: w.ID, EdgeDefault: "directed"}
+	if w.Undirected {
+		xg.EdgeDefault = "undirected"
+	}
+	data, err := ks.data("graph", w.Attributes)
+	if err != nil {
+		return err
+	}
+	xg.Data = data
+
+	ids := make(map[directed.Vertex]string, graph.Order())
+	owner := make(map[string]directed.Vertex, graph.Order())
+	err = graph.DoVertices(func(vertex directed.Vertex) error {
+		id := fmt.Sprint(vertex)
+		if w.VertexID != nil {
+			id = w.VertexID(vertex)
+		}
+		if other, ok := owner[id]; ok {
+			return fmt.Errorf("graphml: vertices %#v and %#v have the same ID %q", other, vertex, id)
+		}
+		owner[id] = vertex
+		ids[vertex] = id
+
+		var attrs Attributes
+		if w.VertexAttributes != nil {
+			attrs = w.VertexAttributes(vertex)
+		}
+		data, err := ks.data("node", attrs)
+		xg.Nodes = append(xg.Nodes, xmlNode{ID: id, Data: data})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	written := make(map[directed.Edge]bool)
+	err = graph.DoEdges(func(source, target directed.Vertex) error {
+		xe := xmlEdge{Source: ids[source], Target: ids[target]}
+		if w.Undirected {
+			if written[directed.Edge{Source: target, Target: source}] {
+				return nil
+			}
+			written[directed.Edge{Source: source, Target: target}] = true
+			if !graph.HasEdge(target, source) {
+				xe.Directed = "true"
+			}
+		}
+		attrs := Attributes{}
+		if w.EdgeAttributes != nil {
+			for name, value := range w.EdgeAttributes(source, target) {
+				attrs[name] = value
+			}
+		}
+		if w.Weight != nil {
+			attrs["weight"] = w.Weight(source, target)
+		}
+		data, err := ks.data("edge", attrs)
+		xe.Data = data
+		xg.Edges = append(xg.Edges, xe)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	doc.Keys = ks.keys
+	doc.Graphs = []xmlGraph{xg}
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\n")
+	return err
+}
+
+// Write will write the graph to 'out' in GraphML format using the
+// string representation of each vertex as its ID.
+func Write(out io.Writer, graph *directed.Graph) error {
+	var w Writer
+	return w.Write(out, graph)
+}
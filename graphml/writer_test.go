@@ -0,0 +1,200 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package graphml
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func TestWrite(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+
+	w := Writer{
+		ID:         "G",
+		Keys:       []Key{{ID: "color", For: "node", Name: "color", Type: "string", Default: "black"}},
+		Attributes: Attributes{"title": "Example"},
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			if vertex == "a" {
+				return Attributes{"color": "red", "size": 3}
+			}
+			return nil
+		},
+		Weight: func(source, target directed.Vertex) float64 {
+			return 1.5
+		},
+	}
+	var out bytes.Buffer
+	if err := w.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="color" for="node" attr.name="color" attr.type="string">
+    <default>black</default>
+  </key>
+  <key id="d1" for="graph" attr.name="title" attr.type="string"></key>
+  <key id="d2" for="node" attr.name="size" attr.type="int"></key>
+  <key id="d3" for="edge" attr.name="weight" attr.type="double"></key>
+  <graph id="G" edgedefault="directed">
+    <data key="d1">Example</data>
+    <node id="a">
+      <data key="color">red</data>
+      <data key="d2">3</data>
+    </node>
+    <node id="b"></node>
+    <node id="c"></node>
+    <edge source="a" target="b">
+      <data key="d3">1.5</data>
+    </edge>
+    <edge source="b" target="c">
+      <data key="d3">1.5</data>
+    </edge>
+  </graph>
+</graphml>
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge(1, "1")
+	var out bytes.Buffer
+	if err := Write(&out, graph); err == nil {
+		t.Errorf("Expected error for duplicate IDs")
+	}
+
+	graph = directed.New()
+	graph.AddEdge(1, 2)
+	w := Writer{
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			if vertex == 1 {
+				return Attributes{"value": 1}
+			}
+			return Attributes{"value": "two"}
+		},
+	}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for conflicting types")
+	}
+
+	w = Writer{Attributes: Attributes{"bad": []int{1}}}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for unsupported type")
+	}
+}
+
+func TestWriteLong(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddVertex("c")
+
+	wide := int64(math.MaxInt32) + 1
+	values := map[directed.Vertex]int{"a": 1, "b": int(wide), "c": -2}
+	w := Writer{
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			return Attributes{"size": values[vertex]}
+		},
+	}
+	var out bytes.Buffer
+	if err := w.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `<key id="d0" for="node" attr.name="size" attr.type="long">`) {
+		t.Errorf("Expected size declared as long:\n%s", out.String())
+	}
+	result, err := Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	for vertex, value := range values {
+		if size := result.VertexAttributes[vertex]["size"]; size != int64(value) {
+			t.Errorf("Expected size %d of %v, got %#v", value, vertex, size)
+		}
+	}
+
+	// An int key declared by the caller cannot hold the value.
+	w.Keys = []Key{{ID: "size", For: "node", Name: "size", Type: "int"}}
+	if err := w.Write(&out, graph); err == nil {
+		t.Errorf("Expected error for value not fitting in int key")
+	}
+
+	// An int value outside of the 32 bits of GraphML is not read.
+	input := `<graphml><key id="k" for="node" attr.type="int"/><graph><node id="n"><data key="k">2147483648</data></node></graph></graphml>`
+	if _, err := Read(strings.NewReader(input)); err == nil {
+		t.Errorf("Expected error for int value out of range")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("x", "y")
+	graph.AddEdge("y", "z")
+	graph.AddEdge("z", "x")
+	graph.AddVertex("<&>")
+
+	vertexAttrs := map[directed.Vertex]Attributes{
+		"x":   {"flag": true, "count": 7, "big": int64(1) << 40},
+		"y":   {"ratio": float32(0.25), "name": "  spaced \"text\" "},
+		"z":   {},
+		"<&>": {"name": "special"},
+	}
+	edgeAttrs := map[directed.Edge]Attributes{
+		{Source: "x", Target: "y"}: {"weight": 2.5},
+		{Source: "y", Target: "z"}: {"weight": 0.1},
+		{Source: "z", Target: "x"}: {},
+	}
+	w := Writer{
+		ID:         "cycle",
+		Attributes: Attributes{"created": int64(20131231)},
+		VertexAttributes: func(vertex directed.Vertex) Attributes {
+			return vertexAttrs[vertex]
+		},
+		EdgeAttributes: func(source, target directed.Vertex) Attributes {
+			return edgeAttrs[directed.Edge{Source: source, Target: target}]
+		},
+	}
+	var out bytes.Buffer
+	if err := w.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	first := out.String()
+
+	result, err := Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if result.ID != "cycle" || !reflect.DeepEqual(result.Attributes, w.Attributes) {
+		t.Errorf("Wrong graph: %q %v", result.ID, result.Attributes)
+	}
+	if !reflect.DeepEqual(result.VertexAttributes, vertexAttrs) {
+		t.Errorf("Expected vertex attributes %v, got %v", vertexAttrs, result.VertexAttributes)
+	}
+	if !reflect.DeepEqual(result.EdgeAttributes, edgeAttrs) {
+		t.Errorf("Expected edge attributes %v, got %v", edgeAttrs, result.EdgeAttributes)
+	}
+	if result.Weight("x", "y") != 2.5 || result.Weight("z", "x") != 1 {
+		t.Errorf("Wrong weights")
+	}
+
+	// Writing the result again should give the same document.
+	out.Reset()
+	if err := result.Writer().Write(&out, result.Graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if out.String() != first {
+		t.Errorf("Expected:\n%s\ngot:\n%s", first, out.String())
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package lowlink implements the bookkeeping of discovery numbers and
+// low-links used by the depth-first algorithms of Tarjan for strongly
+// connected components and of Hopcroft and Tarjan for biconnected
+// components.
+package lowlink
+
+type info struct {
+	number, low int
+}
+
+// Table holds the discovery number and the low-link of each vertex
+// discovered in a depth-first walk. The low-link of a vertex is the
+// lowest discovery number reachable from the subtree rooted at the
+// vertex, where the walker decides what edges to take into account
+// by calling Lower.
+type Table struct {
+	time int
+	info map[interface{}]*info
+}
+
+// New will create an empty table.
+func New() *Table {
+	return &Table{info: make(map[interface{}]*info)}
+}
+
+// Discover will give 'vertex' the next discovery number and set its
+// low-link to the same number.
+func (table *Table) Discover(vertex interface{}) {
+	table.time++
+	table.info[vertex] = &info{number: table.time, low: table.time}
+}
+
+// Number will return the discovery number of 'vertex', or 0 if it has
+// not been discovered.
+func (table *Table) Number(vertex interface{}) int {
+	if vinfo := table.info[vertex]; vinfo != nil {
+		return vinfo.number
+	}
+	return 0
+}
+
+// Low will return the low-link of 'vertex', or 0 if it has not been
+// discovered.
+func (table *Table) Low(vertex interface{}) int {
+	if vinfo := table.info[vertex]; vinfo != nil {
+		return vinfo.low
+	}
+	return 0
+}
+
+// Lower will lower the low-link of 'vertex' to 'low' if that is less
+// than the current low-link.
+func (table *Table) Lower(vertex interface{}, low int) {
+	if vinfo := table.info[vertex]; vinfo != nil && low < vinfo.low {
+		vinfo.low = low
+	}
+}
+
+// Finish will propagate the low-link of 'vertex' to its parent in the
+// depth-first tree. It should be called when 'vertex' is finished,
+// and does nothing if 'parent' is nil.
+func (table *Table) Finish(parent, vertex interface{}) {
+	if parent != nil {
+		table.Lower(parent, table.Low(vertex))
+	}
+}
+
+// IsRoot will return true if no vertex with a lower discovery number
+// can be reached from the subtree rooted at 'vertex'.
+func (table *Table) IsRoot(vertex interface{}) bool {
+	return table.Number(vertex) == table.Low(vertex)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package lowlink
+
+import "testing"
+
+func TestTable(t *testing.T) {
+	table := New()
+	table.Discover("a")
+	table.Discover("b")
+	table.Discover("c")
+	if table.Number("a") != 1 || table.Number("c") != 3 || table.Number("d") != 0 {
+		t.Errorf("Wrong discovery numbers")
+	}
+
+	// Back edge from "c" to "a", which is propagated to "b" when
+	// "c" is finished.
+	table.Lower("c", table.Number("a"))
+	table.Lower("c", table.Number("b"))
+	table.Finish("b", "c")
+	if table.Low("c") != 1 || table.Low("b") != 1 {
+		t.Errorf("Expected low-link 1, got %d and %d", table.Low("b"), table.Low("c"))
+	}
+	table.Finish("a", "b")
+	table.Finish(nil, "a")
+	if !table.IsRoot("a") || table.IsRoot("b") {
+		t.Errorf("Expected only \"a\" to be a root")
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package layout
+
+import (
+	"fmt"
+
+	"github.com/mkindahl/gograph/directed"
+	"github.com/mkindahl/gograph/dot"
+	"github.com/mkindahl/gograph/gexf"
+)
+
+// DotAttributes will return the position of a vertex as a DOT "pos"
+// attribute, pinned so that Graphviz keeps it. It can be used as the
+// VertexAttributes of a dot.Writer, and the output drawn with, for
+// example, "neato -n". Since y grows upwards in DOT, the drawing is
+// flipped vertically.
+func (layout *Layout) DotAttributes(vertex directed.Vertex) dot.Attributes {
+	p, ok := layout.Positions[vertex]
+	if !ok {
+		return nil
+	}
+	return dot.Attributes{"pos": fmt.Sprintf("%s,%s!", num(p.X), num(layout.Height-p.Y))}
+}
+
+// GEXFElement will return the data of a vertex with its position
+// set. It can be used as the Vertex function of a gexf.Writer. As for
+// DOT, the drawing is flipped vertically.
+func (layout *Layout) GEXFElement(vertex directed.Vertex) *gexf.Element {
+	elem := &gexf.Element{Label: fmt.Sprint(vertex)}
+	if p, ok := layout.Positions[vertex]; ok {
+		elem.Viz.Position = &gexf.Position{X: p.X, Y: layout.Height - p.Y}
+	}
+	return elem
+}
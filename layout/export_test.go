@@ -0,0 +1,57 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package layout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+	"github.com/mkindahl/gograph/dot"
+	"github.com/mkindahl/gograph/gexf"
+)
+
+func TestExport(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	layout := Layered(graph, nil)
+
+	var out bytes.Buffer
+	w := dot.Writer{VertexAttributes: layout.DotAttributes}
+	if err := w.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	result, err := dot.Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if pos := result.VertexAttributes["a"]["pos"]; pos != "40,120!" {
+		t.Errorf("Wrong DOT position %q", pos)
+	}
+	if pos := result.VertexAttributes["b"]["pos"]; pos != "40,40!" {
+		t.Errorf("Wrong DOT position %q", pos)
+	}
+
+	out.Reset()
+	gw := gexf.Writer{Vertex: layout.GEXFElement}
+	if err := gw.Write(&out, graph); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `<position xmlns="http://gexf.net/1.3/viz" x="40" y="120"></position>`) {
+		t.Errorf("No GEXF position in:\n%s", out.String())
+	}
+	read, err := gexf.Read(&out)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if p := read.Vertices["b"].Viz.Position; p == nil || p.X != 40 || p.Y != 40 {
+		t.Errorf("Wrong GEXF position %+v", p)
+	}
+	if layout.DotAttributes("missing") != nil {
+		t.Errorf("Expected no attributes for missing vertex")
+	}
+}
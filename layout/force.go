@@ -0,0 +1,141 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package layout
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// ForceOptions control the force-directed layout. A nil *ForceOptions
+// is the same as the zero value, and zero fields other than the seed
+// get default values.
+type ForceOptions struct {
+	// Seed for the random initial positions. The same graph laid
+	// out with the same seed gets the same layout.
+	Seed int64
+
+	// Iterations is the number of steps of the simulation. The
+	// default is 300.
+	Iterations int
+
+	// Distance is the ideal distance between connected vertices.
+	// The default is 80.
+	Distance float64
+}
+
+// ForceDirected will lay out the graph using the force-directed
+// method of Fruchterman and Reingold, which works well for graphs
+// that do not have a natural direction. All vertices repel each
+// other, while the vertices of each edge attract each other as if
+// connected by a spring, ignoring the direction of the edge. The
+// vertices start at random positions and are moved by the forces, at
+// most a distance that decreases with each step, so that the layout
+// settles.
+//
+// Each step compares all pairs of vertices, so the time is quadratic
+// in the number of vertices. The edges are drawn as straight lines,
+// so the layout has no routes.
+func ForceDirected(graph *directed.Graph, opts *ForceOptions) *Layout {
+	if opts == nil {
+		opts = &ForceOptions{}
+	}
+	iterations, distance := opts.Iterations, opts.Distance
+	if iterations <= 0 {
+		iterations = 300
+	}
+	if distance <= 0 {
+		distance = 80
+	}
+
+	var vertices []directed.Vertex
+	index := make(map[directed.Vertex]int, graph.Order())
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		index[vertex] = len(vertices)
+		vertices = append(vertices, vertex)
+		return nil
+	})
+	var springs [][2]int
+	seen := make(map[[2]int]bool)
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		v, w := index[source], index[target]
+		if v > w {
+			v, w = w, v
+		}
+		if v != w && !seen[[2]int{v, w}] {
+			seen[[2]int{v, w}] = true
+			springs = append(springs, [2]int{v, w})
+		}
+		return nil
+	})
+
+	// The vertices start spread over a square with room for each
+	// vertex, which is also used to limit how far they can move.
+	side := distance * math.Sqrt(float64(len(vertices)))
+	random := rand.New(rand.NewSource(opts.Seed))
+	pos := make([]Point, len(vertices))
+	for i := range pos {
+		pos[i] = Point{random.Float64() * side, random.Float64() * side}
+	}
+
+	disp := make([]Point, len(vertices))
+	k2 := distance * distance
+	for step := 0; step < iterations; step++ {
+		for i := range disp {
+			disp[i] = Point{}
+		}
+		for i := range pos {
+			for j := i + 1; j < len(pos); j++ {
+				dx, dy := pos[i].X-pos[j].X, pos[i].Y-pos[j].Y
+				d2 := dx*dx + dy*dy
+				if d2 == 0 {
+					// Vertices at the same position are pushed
+					// apart in a direction given by their
+					// numbers, so that the result is still
+					// deterministic.
+					angle := float64(i*len(pos)+j) * 2.399963
+					dx, dy, d2 = math.Cos(angle), math.Sin(angle), 1
+				}
+				// The repulsion is k²/d along the unit vector,
+				// which is k²/d² times the difference.
+				f := k2 / d2
+				disp[i].X += dx * f
+				disp[i].Y += dy * f
+				disp[j].X -= dx * f
+				disp[j].Y -= dy * f
+			}
+		}
+		for _, spring := range springs {
+			v, w := spring[0], spring[1]
+			dx, dy := pos[v].X-pos[w].X, pos[v].Y-pos[w].Y
+			// The attraction is d²/k along the unit vector, which
+			// is d/k times the difference.
+			f := math.Sqrt(dx*dx+dy*dy) / distance
+			disp[v].X -= dx * f
+			disp[v].Y -= dy * f
+			disp[w].X += dx * f
+			disp[w].Y += dy * f
+		}
+
+		temperature := side / 10 * float64(iterations-step) / float64(iterations)
+		for i, d := range disp {
+			length := math.Sqrt(d.X*d.X + d.Y*d.Y)
+			if length > temperature {
+				d.X, d.Y = d.X/length*temperature, d.Y/length*temperature
+			}
+			pos[i] = Point{pos[i].X + d.X, pos[i].Y + d.Y}
+		}
+	}
+
+	layout := newLayout()
+	for i, vertex := range vertices {
+		layout.Positions[vertex] = pos[i]
+	}
+	layout.normalize(distance / 2)
+	return layout
+}
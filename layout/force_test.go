@@ -0,0 +1,85 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package layout
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// dist will return the distance between two points.
+func dist(p, q Point) float64 {
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+func TestForceDirected(t *testing.T) {
+	graph := directed.New()
+	for i := 0; i < 6; i++ {
+		graph.AddEdge(i, (i+1)%6)
+	}
+	graph.AddEdge(1, 0)
+	graph.AddEdge(3, 3)
+
+	layout := ForceDirected(graph, &ForceOptions{Seed: 1})
+	pos := layout.Positions
+	if len(pos) != 6 || len(layout.Routes) != 0 {
+		t.Fatalf("Wrong layout %+v", layout)
+	}
+	for i := 0; i < 6; i++ {
+		next, opposite := dist(pos[i], pos[(i+1)%6]), dist(pos[i], pos[(i+3)%6])
+		if next >= opposite {
+			t.Errorf("Neighbours %d and %d further apart than %d and %d", i, (i+1)%6, i, (i+3)%6)
+		}
+		if next < 40 || next > 160 {
+			t.Errorf("Edge length %v far from ideal", next)
+		}
+	}
+	for _, p := range pos {
+		if p.X < 40 || p.Y < 40 || p.X > layout.Width-40 || p.Y > layout.Height-40 {
+			t.Errorf("Position %v outside margin of %vx%v drawing", p, layout.Width, layout.Height)
+		}
+	}
+
+	if again := ForceDirected(graph, &ForceOptions{Seed: 1}); !reflect.DeepEqual(again, layout) {
+		t.Errorf("Layout is not deterministic")
+	}
+	if other := ForceDirected(graph, &ForceOptions{Seed: 2}); reflect.DeepEqual(other, layout) {
+		t.Errorf("Seed has no effect")
+	}
+}
+
+func TestForceDirectedSpread(t *testing.T) {
+	// Vertices without edges should still be kept apart.
+	graph := directed.New()
+	for i := 0; i < 10; i++ {
+		graph.AddVertex(i)
+	}
+	layout := ForceDirected(graph, &ForceOptions{Distance: 20, Iterations: 100})
+	for v, p := range layout.Positions {
+		for w, q := range layout.Positions {
+			if v != w && dist(p, q) < 10 {
+				t.Errorf("Vertices %v and %v too close: %v and %v", v, w, p, q)
+			}
+		}
+	}
+}
+
+func TestForceDirectedSmall(t *testing.T) {
+	layout := ForceDirected(directed.New(), nil)
+	if len(layout.Positions) != 0 || layout.Width != 80 {
+		t.Errorf("Wrong empty layout %+v", layout)
+	}
+
+	graph := directed.New()
+	graph.AddVertex("alone")
+	layout = ForceDirected(graph, nil)
+	if p := layout.Positions["alone"]; p.X != 40 || p.Y != 40 {
+		t.Errorf("Wrong position %v of single vertex", p)
+	}
+}
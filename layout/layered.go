@@ -0,0 +1,423 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package layout
+
+import (
+	"sort"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// LayeredOptions control the layered layout. A nil *LayeredOptions is
+// the same as the zero value, and zero fields get default values.
+type LayeredOptions struct {
+	// LayerSpacing is the distance between layers. The default is
+	// 80.
+	LayerSpacing float64
+
+	// VertexSpacing is the minimum distance between the centers of
+	// vertices in the same layer. The default is 80.
+	VertexSpacing float64
+
+	// Sweeps is the number of sweeps over the layers done to
+	// reduce the number of edge crossings. The default is 8.
+	Sweeps int
+}
+
+// layered is the state of a layered layout. The nodes are the
+// vertices of the graph, numbered in the order they were added,
+// followed by dummy nodes inserted where edges cross layers.
+type layered struct {
+	layer  []int
+	up     [][]int
+	down   [][]int
+	layers [][]int
+	pos    []int
+}
+
+// addNode will add a node to a layer and return its number.
+func (l *layered) addNode(layer int) int {
+	l.layer = append(l.layer, layer)
+	l.up = append(l.up, nil)
+	l.down = append(l.down, nil)
+	return len(l.layer) - 1
+}
+
+// link will add a link between nodes in adjacent layers.
+func (l *layered) link(upper, lower int) {
+	l.down[upper] = append(l.down[upper], lower)
+	l.up[lower] = append(l.up[lower], upper)
+}
+
+// Layered will lay out the graph in layers, so that edges point
+// downwards as far as possible, using the method of Sugiyama, Tagawa,
+// and Toda:
+//
+// 1. Cycles are broken by reversing the edges that go back in a
+// depth-first search.
+//
+// 2. Each vertex is placed in a layer one below the lowest of its
+// predecessors.
+//
+// 3. Edges spanning several layers are split with dummy vertices, so
+// that they can bend around other vertices.
+//
+// 4. The vertices of each layer are ordered by the average position
+// of their neighbours in the adjacent layer, sweeping up and down and
+// keeping the order with the fewest crossings.
+//
+// 5. The vertices are placed as close to the average of their
+// neighbours as the minimum spacing permits.
+//
+// Edges that span more than one layer are given routes through the
+// dummy vertices. Edges from a vertex to itself are ignored by the
+// layout. The layout is deterministic for a given graph, since it
+// only depends on the order of the vertices and edges.
+func Layered(graph *directed.Graph, opts *LayeredOptions) *Layout {
+	if opts == nil {
+		opts = &LayeredOptions{}
+	}
+	layerSpacing, vertexSpacing, sweeps := opts.LayerSpacing, opts.VertexSpacing, opts.Sweeps
+	if layerSpacing <= 0 {
+		layerSpacing = 80
+	}
+	if vertexSpacing <= 0 {
+		vertexSpacing = 80
+	}
+	if sweeps <= 0 {
+		sweeps = 8
+	}
+
+	var vertices []directed.Vertex
+	index := make(map[directed.Vertex]int, graph.Order())
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		index[vertex] = len(vertices)
+		vertices = append(vertices, vertex)
+		return nil
+	})
+	succ := make([][]int, len(vertices))
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		if source != target {
+			succ[index[source]] = append(succ[index[source]], index[target])
+		}
+		return nil
+	})
+
+	reversed := breakCycles(succ)
+	dag := make([][]int, len(vertices))
+	seen := make(map[[2]int]bool)
+	for v, targets := range succ {
+		for _, w := range targets {
+			edge := [2]int{v, w}
+			if reversed[edge] {
+				edge = [2]int{w, v}
+			}
+			if !seen[edge] {
+				seen[edge] = true
+				dag[edge[0]] = append(dag[edge[0]], edge[1])
+			}
+		}
+	}
+
+	l := &layered{}
+	for range vertices {
+		l.addNode(0)
+	}
+	assignLayers(dag, l.layer)
+
+	chains := make(map[[2]int][]int)
+	for u, targets := range dag {
+		for _, w := range targets {
+			chain := []int{u}
+			for layer := l.layer[u] + 1; layer < l.layer[w]; layer++ {
+				dummy := l.addNode(layer)
+				l.link(chain[len(chain)-1], dummy)
+				chain = append(chain, dummy)
+			}
+			l.link(chain[len(chain)-1], w)
+			chains[[2]int{u, w}] = append(chain, w)
+		}
+	}
+
+	l.order(sweeps)
+	x := l.coordinates(vertexSpacing)
+
+	layout := newLayout()
+	point := func(node int) Point {
+		return Point{x[node], float64(l.layer[node]) * layerSpacing}
+	}
+	for v, vertex := range vertices {
+		layout.Positions[vertex] = point(v)
+	}
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		v, w := index[source], index[target]
+		chain := chains[[2]int{v, w}]
+		if reversed[[2]int{v, w}] {
+			chain = reversedChain(chains[[2]int{w, v}])
+		}
+		if len(chain) > 2 {
+			route := make([]Point, len(chain))
+			for i, node := range chain {
+				route[i] = point(node)
+			}
+			layout.Routes[directed.Edge{Source: source, Target: target}] = route
+		}
+		return nil
+	})
+	layout.normalize(vertexSpacing / 2)
+	return layout
+}
+
+// reversedChain will return a copy of the chain in reverse order.
+func reversedChain(chain []int) []int {
+	result := make([]int, len(chain))
+	for i, node := range chain {
+		result[len(chain)-1-i] = node
+	}
+	return result
+}
+
+// breakCycles will do a depth-first search over the nodes and return
+// the edges going back to a node on the stack. Reversing these edges
+// will make the graph acyclic.
+func breakCycles(succ [][]int) map[[2]int]bool {
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	type frame struct {
+		node, next int
+	}
+	reversed := make(map[[2]int]bool)
+	state := make([]int, len(succ))
+	for root := range succ {
+		if state[root] != unvisited {
+			continue
+		}
+		state[root] = active
+		stack := []frame{{root, 0}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next == len(succ[top.node]) {
+				state[top.node] = done
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			next := succ[top.node][top.next]
+			top.next++
+			switch state[next] {
+			case active:
+				reversed[[2]int{top.node, next}] = true
+			case unvisited:
+				state[next] = active
+				stack = append(stack, frame{next, 0})
+			}
+		}
+	}
+	return reversed
+}
+
+// assignLayers will place each node of the acyclic graph one layer
+// below the lowest of its predecessors, processing the nodes in
+// topological order.
+func assignLayers(dag [][]int, layer []int) {
+	indegree := make([]int, len(dag))
+	for _, targets := range dag {
+		for _, w := range targets {
+			indegree[w]++
+		}
+	}
+	var queue []int
+	for v := range dag {
+		if indegree[v] == 0 {
+			queue = append(queue, v)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range dag[v] {
+			if layer[w] < layer[v]+1 {
+				layer[w] = layer[v] + 1
+			}
+			indegree[w]--
+			if indegree[w] == 0 {
+				queue = append(queue, w)
+			}
+		}
+	}
+}
+
+// order will order the nodes of each layer to reduce the number of
+// crossings using the barycenter heuristic.
+func (l *layered) order(sweeps int) {
+	depth := 0
+	for _, layer := range l.layer {
+		if layer+1 > depth {
+			depth = layer + 1
+		}
+	}
+	l.layers = make([][]int, depth)
+	l.pos = make([]int, len(l.layer))
+	for node, layer := range l.layer {
+		l.pos[node] = len(l.layers[layer])
+		l.layers[layer] = append(l.layers[layer], node)
+	}
+
+	best := l.save()
+	fewest := l.crossings()
+	for sweep := 0; sweep < sweeps && fewest > 0; sweep++ {
+		if sweep%2 == 0 {
+			for layer := 1; layer < len(l.layers); layer++ {
+				l.reorder(l.layers[layer], l.up)
+			}
+		} else {
+			for layer := len(l.layers) - 2; layer >= 0; layer-- {
+				l.reorder(l.layers[layer], l.down)
+			}
+		}
+		if count := l.crossings(); count < fewest {
+			best, fewest = l.save(), count
+		}
+	}
+	l.layers = best
+	for _, nodes := range l.layers {
+		for i, node := range nodes {
+			l.pos[node] = i
+		}
+	}
+}
+
+// save will return a copy of the order of the layers.
+func (l *layered) save() [][]int {
+	result := make([][]int, len(l.layers))
+	for i, nodes := range l.layers {
+		result[i] = append([]int(nil), nodes...)
+	}
+	return result
+}
+
+// reorder will sort the nodes of a layer by the average position of
+// their neighbours. Nodes without neighbours keep their position.
+func (l *layered) reorder(nodes []int, neighbours [][]int) {
+	center := make(map[int]float64, len(nodes))
+	for _, node := range nodes {
+		if len(neighbours[node]) == 0 {
+			center[node] = float64(l.pos[node])
+			continue
+		}
+		sum := 0
+		for _, other := range neighbours[node] {
+			sum += l.pos[other]
+		}
+		center[node] = float64(sum) / float64(len(neighbours[node]))
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return center[nodes[i]] < center[nodes[j]]
+	})
+	for i, node := range nodes {
+		l.pos[node] = i
+	}
+}
+
+// crossings will count the number of crossing links between adjacent
+// layers, by counting inversions of the lower ends when the links are
+// sorted by their upper ends.
+func (l *layered) crossings() int {
+	count := 0
+	for layer := 0; layer+1 < len(l.layers); layer++ {
+		var lower []int
+		for _, node := range l.layers[layer] {
+			targets := make([]int, 0, len(l.down[node]))
+			for _, other := range l.down[node] {
+				targets = append(targets, l.pos[other])
+			}
+			sort.Ints(targets)
+			lower = append(lower, targets...)
+		}
+
+		// A Fenwick tree of the lower ends seen so far gives the
+		// number of earlier links that end to the right.
+		tree := make([]int, len(l.layers[layer+1])+1)
+		for seen, p := range lower {
+			for i := p + 1; i > 0; i -= i & -i {
+				seen -= tree[i]
+			}
+			count += seen
+			for i := p + 1; i < len(tree); i += i & -i {
+				tree[i]++
+			}
+		}
+	}
+	return count
+}
+
+// coordinates will assign horizontal coordinates to the nodes,
+// keeping the order of each layer and the minimum spacing, by
+// repeatedly moving the nodes of each layer towards the average of
+// their neighbours in the previous layer.
+func (l *layered) coordinates(spacing float64) []float64 {
+	x := make([]float64, len(l.layer))
+	for node, p := range l.pos {
+		x[node] = float64(p) * spacing
+	}
+	align := func(nodes []int, neighbours [][]int) {
+		desired := make([]float64, len(nodes))
+		for i, node := range nodes {
+			desired[i] = x[node]
+			if len(neighbours[node]) > 0 {
+				sum := 0.0
+				for _, other := range neighbours[node] {
+					sum += x[other]
+				}
+				desired[i] = sum / float64(len(neighbours[node]))
+			}
+		}
+		for i, value := range place(desired, spacing) {
+			x[nodes[i]] = value
+		}
+	}
+	for pass := 0; pass < 4; pass++ {
+		for layer := 1; layer < len(l.layers); layer++ {
+			align(l.layers[layer], l.up)
+		}
+		for layer := len(l.layers) - 2; layer >= 0; layer-- {
+			align(l.layers[layer], l.down)
+		}
+	}
+	return x
+}
+
+// place will return the positions closest to the desired positions,
+// in the least squares sense, that keep the order and are at least
+// 'spacing' apart. Subtracting the minimum spacing turns this into
+// finding the closest non-decreasing sequence, which is solved by
+// pooling adjacent values that are out of order.
+func place(desired []float64, spacing float64) []float64 {
+	type block struct {
+		sum   float64
+		count int
+	}
+	mean := func(b block) float64 { return b.sum / float64(b.count) }
+	var blocks []block
+	for i, value := range desired {
+		blocks = append(blocks, block{value - float64(i)*spacing, 1})
+		for len(blocks) > 1 && mean(blocks[len(blocks)-2]) > mean(blocks[len(blocks)-1]) {
+			last := blocks[len(blocks)-1]
+			blocks = blocks[:len(blocks)-1]
+			blocks[len(blocks)-1].sum += last.sum
+			blocks[len(blocks)-1].count += last.count
+		}
+	}
+	result := make([]float64, 0, len(desired))
+	for _, b := range blocks {
+		for k := 0; k < b.count; k++ {
+			result = append(result, mean(b)+float64(len(result))*spacing)
+		}
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package layout
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// checkSpacing will check that vertices at the same height are at
+// least 'spacing' apart.
+func checkSpacing(t *testing.T, layout *Layout, spacing float64) {
+	for v, p := range layout.Positions {
+		for w, q := range layout.Positions {
+			if v != w && p.Y == q.Y && math.Abs(p.X-q.X) < spacing-1e-9 {
+				t.Errorf("Vertices %v at %v and %v at %v too close", v, p, w, q)
+			}
+		}
+	}
+}
+
+func TestLayered(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+	graph.AddEdge("c", "d")
+	graph.AddEdge("a", "d")
+	graph.AddEdge("a", "e")
+	graph.AddEdge("e", "d")
+
+	layout := Layered(graph, nil)
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		if layout.Positions[source].Y >= layout.Positions[target].Y {
+			t.Errorf("Edge %v -> %v does not point down", source, target)
+		}
+		return nil
+	})
+	checkSpacing(t, layout, 80)
+
+	route := layout.Routes[directed.Edge{Source: "a", Target: "d"}]
+	if len(route) != 4 || route[0] != layout.Positions["a"] || route[3] != layout.Positions["d"] {
+		t.Errorf("Wrong route %v for long edge", route)
+	}
+	if _, ok := layout.Routes[directed.Edge{Source: "a", Target: "b"}]; ok {
+		t.Errorf("Short edge has a route")
+	}
+	for _, p := range layout.Positions {
+		if p.X < 40 || p.Y < 40 || p.X > layout.Width-40 || p.Y > layout.Height-40 {
+			t.Errorf("Position %v outside margin of %vx%v drawing", p, layout.Width, layout.Height)
+		}
+	}
+
+	if again := Layered(graph, nil); !reflect.DeepEqual(again, layout) {
+		t.Errorf("Layout is not deterministic")
+	}
+}
+
+func TestLayeredCrossings(t *testing.T) {
+	graph := directed.New()
+	graph.AddVertex("a")
+	graph.AddVertex("b")
+	graph.AddVertex("c")
+	graph.AddVertex("d")
+	graph.AddEdge("a", "d")
+	graph.AddEdge("b", "c")
+
+	layout := Layered(graph, &LayeredOptions{VertexSpacing: 50, LayerSpacing: 100})
+	pos := layout.Positions
+	if (pos["a"].X < pos["b"].X) != (pos["d"].X < pos["c"].X) {
+		t.Errorf("Edges cross: %v", pos)
+	}
+	if pos["c"].Y-pos["a"].Y != 100 {
+		t.Errorf("Wrong layer spacing: %v", pos)
+	}
+	checkSpacing(t, layout, 50)
+}
+
+func TestLayeredCycles(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+	graph.AddEdge("c", "a")
+	graph.AddEdge("b", "a")
+	graph.AddEdge("c", "c")
+
+	layout := Layered(graph, nil)
+	if len(layout.Positions) != 3 {
+		t.Fatalf("Expected 3 positions, got %v", layout.Positions)
+	}
+	a, b, c := layout.Positions["a"], layout.Positions["b"], layout.Positions["c"]
+	if !(a.Y < b.Y && b.Y < c.Y) {
+		t.Errorf("Expected layers a, b, c, got %v", layout.Positions)
+	}
+	route := layout.Routes[directed.Edge{Source: "c", Target: "a"}]
+	if len(route) != 3 || route[0] != c || route[2] != a {
+		t.Errorf("Wrong route %v for reversed edge", route)
+	}
+}
+
+func TestLayeredEmpty(t *testing.T) {
+	layout := Layered(directed.New(), nil)
+	if len(layout.Positions) != 0 || layout.Width != 80 || layout.Height != 80 {
+		t.Errorf("Wrong empty layout %+v", layout)
+	}
+}
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		desired  []float64
+		expected []float64
+	}{
+		{[]float64{0, 100, 200}, []float64{0, 100, 200}},
+		{[]float64{0, 0, 0}, []float64{-10, 0, 10}},
+		{[]float64{50, 0, 100}, []float64{20, 30, 100}},
+	}
+	for _, test := range tests {
+		if result := place(test.desired, 10); !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Expected %v for %v, got %v", test.expected, test.desired, result)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package layout implements computing positions for the vertices of a
+// directed graph so that it can be drawn, and drawing it as an SVG
+// image, without depending on external tools such as Graphviz.
+package layout
+
+import (
+	"math"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// Point is a position in a drawing. The origin is in the top left
+// corner and y grows downwards, as in SVG.
+type Point struct {
+	X, Y float64
+}
+
+// Layout is the result of laying out a graph.
+type Layout struct {
+	// Positions are the centers of the vertices.
+	Positions map[directed.Vertex]Point
+
+	// Routes are the points that edges that are not drawn as
+	// straight lines pass through, starting with the position of
+	// the source and ending with the position of the target.
+	Routes map[directed.Edge][]Point
+
+	// Width and Height are the size of the drawing, including a
+	// margin around the vertices.
+	Width, Height float64
+}
+
+// newLayout will create an empty layout.
+func newLayout() *Layout {
+	return &Layout{
+		Positions: make(map[directed.Vertex]Point),
+		Routes:    make(map[directed.Edge][]Point),
+	}
+}
+
+// normalize will move the drawing so that the vertices and routes
+// start at the margin and set the size of the drawing.
+func (layout *Layout) normalize(margin float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	extend := func(p Point) {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	for _, p := range layout.Positions {
+		extend(p)
+	}
+	for _, route := range layout.Routes {
+		for _, p := range route {
+			extend(p)
+		}
+	}
+	if len(layout.Positions) == 0 {
+		layout.Width, layout.Height = 2*margin, 2*margin
+		return
+	}
+
+	dx, dy := margin-minX, margin-minY
+	for vertex, p := range layout.Positions {
+		layout.Positions[vertex] = Point{p.X + dx, p.Y + dy}
+	}
+	for _, route := range layout.Routes {
+		for i, p := range route {
+			route[i] = Point{p.X + dx, p.Y + dy}
+		}
+	}
+	layout.Width = maxX - minX + 2*margin
+	layout.Height = maxY - minY + 2*margin
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package layout
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// SVGOptions control how a graph is drawn. A nil *SVGOptions is the
+// same as the zero value.
+type SVGOptions struct {
+	// VertexLabel is called to get the label of each vertex. If
+	// nil, the string representation of the vertex is used.
+	VertexLabel func(vertex directed.Vertex) string
+
+	// FontSize of the labels. The default is 14.
+	FontSize float64
+}
+
+// num will format a coordinate with at most two decimals.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// box is the rectangle drawn for a vertex.
+type box struct {
+	center Point
+	halfW  float64
+	halfH  float64
+}
+
+// boundary will return the point where a line from the center of the
+// box towards 'p' leaves the box.
+func (b box) boundary(p Point) Point {
+	dx, dy := p.X-b.center.X, p.Y-b.center.Y
+	if dx == 0 && dy == 0 {
+		return b.center
+	}
+	scale := math.Inf(1)
+	if dx != 0 {
+		scale = b.halfW / math.Abs(dx)
+	}
+	if dy != 0 {
+		scale = math.Min(scale, b.halfH/math.Abs(dy))
+	}
+	if scale > 1 {
+		scale = 1
+	}
+	return Point{b.center.X + dx*scale, b.center.Y + dy*scale}
+}
+
+// WriteSVG will write a drawing of the graph with the given layout to
+// 'out' as a self-contained SVG image, which can be saved as a file or
+// embedded directly in an HTML page. The vertices are drawn as boxes
+// with their labels and the edges as lines, or paths following their
+// routes, with arrow heads. An error is returned if a vertex has no
+// position in the layout.
+func WriteSVG(out io.Writer, graph *directed.Graph, layout *Layout, opts *SVGOptions) error {
+	if opts == nil {
+		opts = &SVGOptions{}
+	}
+	fontSize := opts.FontSize
+	if fontSize <= 0 {
+		fontSize = 14
+	}
+
+	labels := make(map[directed.Vertex]string, graph.Order())
+	boxes := make(map[directed.Vertex]box, graph.Order())
+	err := graph.DoVertices(func(vertex directed.Vertex) error {
+		center, ok := layout.Positions[vertex]
+		if !ok {
+			return fmt.Errorf("layout: no position for vertex %v", vertex)
+		}
+		label := fmt.Sprint(vertex)
+		if opts.VertexLabel != nil {
+			label = opts.VertexLabel(vertex)
+		}
+		// The width is estimated from the number of characters,
+		// since the actual font is not known.
+		height := 2 * fontSize
+		width := math.Max(height, float64(utf8.RuneCountInString(label))*fontSize*0.6+fontSize)
+		labels[vertex] = label
+		boxes[vertex] = box{center, width / 2, height / 2}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(out)
+	fmt.Fprintf(buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		num(layout.Width), num(layout.Height), num(layout.Width), num(layout.Height))
+	buf.WriteString("  <defs>\n")
+	buf.WriteString("    <marker id=\"arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\"" +
+		" markerWidth=\"8\" markerHeight=\"8\" orient=\"auto\">\n")
+	buf.WriteString("      <path d=\"M 0 0 L 10 5 L 0 10 z\" fill=\"#333\"/>\n")
+	buf.WriteString("    </marker>\n")
+	buf.WriteString("  </defs>\n")
+
+	buf.WriteString("  <g fill=\"none\" stroke=\"#333\" stroke-width=\"1.5\">\n")
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		from, to := boxes[source], boxes[target]
+		var d string
+		if source == target {
+			// A loop is drawn from the top to the right side of
+			// the box.
+			start := Point{from.center.X + from.halfW/2, from.center.Y - from.halfH}
+			end := Point{from.center.X + from.halfW, from.center.Y - from.halfH/2}
+			d = fmt.Sprintf("M %s %s C %s %s %s %s %s %s",
+				num(start.X), num(start.Y),
+				num(start.X), num(start.Y-2*from.halfH),
+				num(end.X+2*from.halfH), num(end.Y),
+				num(end.X), num(end.Y))
+		} else {
+			points := layout.Routes[directed.Edge{Source: source, Target: target}]
+			if len(points) < 2 {
+				points = []Point{from.center, to.center}
+			}
+			points = append([]Point(nil), points...)
+			points[0] = from.boundary(points[1])
+			points[len(points)-1] = to.boundary(points[len(points)-2])
+			parts := make([]string, len(points))
+			for i, p := range points {
+				parts[i] = num(p.X) + " " + num(p.Y)
+			}
+			d = "M " + strings.Join(parts, " L ")
+		}
+		fmt.Fprintf(buf, "    <path d=\"%s\" marker-end=\"url(#arrow)\"/>\n", d)
+		return nil
+	})
+	buf.WriteString("  </g>\n")
+
+	fmt.Fprintf(buf, "  <g font-family=\"sans-serif\" font-size=\"%s\" text-anchor=\"middle\">\n", num(fontSize))
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		b := boxes[vertex]
+		fmt.Fprintf(buf, "    <rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" rx=\"4\" fill=\"#fff\" stroke=\"#333\"/>\n",
+			num(b.center.X-b.halfW), num(b.center.Y-b.halfH), num(2*b.halfW), num(2*b.halfH))
+		fmt.Fprintf(buf, "    <text x=\"%s\" y=\"%s\" dominant-baseline=\"central\">%s</text>\n",
+			num(b.center.X), num(b.center.Y), html.EscapeString(labels[vertex]))
+		return nil
+	})
+	buf.WriteString("  </g>\n")
+	buf.WriteString("</svg>\n")
+	return buf.Flush()
+}
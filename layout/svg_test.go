@@ -0,0 +1,109 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package layout
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func TestWriteSVG(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("a", "c")
+	graph.AddEdge("b", "b")
+
+	var out bytes.Buffer
+	if err := WriteSVG(&out, graph, Layered(graph, nil), nil); err != nil {
+		t.Fatalf("WriteSVG failed: %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" width="160" height="160" viewBox="0 0 160 160">
+  <defs>
+    <marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto">
+      <path d="M 0 0 L 10 5 L 0 10 z" fill="#333"/>
+    </marker>
+  </defs>
+  <g fill="none" stroke="#333" stroke-width="1.5">
+    <path d="M 73 54 L 47 106" marker-end="url(#arrow)"/>
+    <path d="M 87 54 L 113 106" marker-end="url(#arrow)"/>
+    <path d="M 47 106 C 47 78 82 113 54 113" marker-end="url(#arrow)"/>
+  </g>
+  <g font-family="sans-serif" font-size="14" text-anchor="middle">
+    <rect x="66" y="26" width="28" height="28" rx="4" fill="#fff" stroke="#333"/>
+    <text x="80" y="40" dominant-baseline="central">a</text>
+    <rect x="26" y="106" width="28" height="28" rx="4" fill="#fff" stroke="#333"/>
+    <text x="40" y="120" dominant-baseline="central">b</text>
+    <rect x="106" y="106" width="28" height="28" rx="4" fill="#fff" stroke="#333"/>
+    <text x="120" y="120" dominant-baseline="central">c</text>
+  </g>
+</svg>
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteSVGRoutes(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("start", "middle")
+	graph.AddEdge("middle", "<end & more>")
+	graph.AddEdge("start", "<end & more>")
+
+	var out bytes.Buffer
+	opts := &SVGOptions{
+		FontSize: 10,
+		VertexLabel: func(vertex directed.Vertex) string {
+			return strings.ToUpper(vertex.(string))
+		},
+	}
+	if err := WriteSVG(&out, graph, Layered(graph, nil), opts); err != nil {
+		t.Fatalf("WriteSVG failed: %v", err)
+	}
+
+	// The output has to be well-formed XML with one path for each
+	// edge, where the long edge bends through a dummy vertex.
+	decoder := xml.NewDecoder(&out)
+	var paths, texts []string
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Output is not well-formed: %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if tok.Name.Local == "path" && len(tok.Attr) == 2 && tok.Attr[1].Name.Local == "marker-end" {
+				paths = append(paths, tok.Attr[0].Value)
+			}
+		case xml.CharData:
+			if text := strings.TrimSpace(string(tok)); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	if len(paths) != 3 || strings.Count(paths[1], "L") != 2 {
+		t.Errorf("Wrong paths %q", paths)
+	}
+	if len(texts) != 3 || texts[2] != "<END & MORE>" {
+		t.Errorf("Wrong labels %q", texts)
+	}
+}
+
+func TestWriteSVGErrors(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge(1, 2)
+	layout := Layered(graph, nil)
+	graph.AddVertex(3)
+	if err := WriteSVG(io.Discard, graph, layout, nil); err == nil {
+		t.Errorf("Expected error for vertex without position")
+	}
+}
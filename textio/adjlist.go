@@ -0,0 +1,72 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package textio
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// ReadAdjacencyList will read a graph given as an adjacency list from
+// 'in'. Each line contains a vertex followed by the targets of its
+// out-edges, separated by white space. A vertex can occur on several
+// lines, and a line with only a vertex adds the vertex without any
+// edges. Adjacency lists have no weights, so the Weighted option is
+// ignored.
+func ReadAdjacencyList(in io.Reader, opts *Options) (*directed.Graph, error) {
+	graph := directed.New()
+	add := addEdge(graph, nil, opts)
+	ls := newLineScanner(in, opts.comments(), opts.maxLineLength())
+	for fields := ls.next(); fields != nil; fields = ls.next() {
+		source, err := opts.parseVertex(fields[0])
+		if err != nil {
+			return nil, ls.errorf("bad vertex %q: %v", fields[0], err)
+		}
+		graph.AddVertex(source)
+		for _, field := range fields[1:] {
+			target, err := opts.parseVertex(field)
+			if err != nil {
+				return nil, ls.errorf("bad vertex %q: %v", field, err)
+			}
+			add(source, target, 1)
+		}
+	}
+	if err := ls.err(); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// WriteAdjacencyList will write the graph to 'out' as an adjacency
+// list, with one line for each vertex in the order they were added to
+// the graph.
+func WriteAdjacencyList(out io.Writer, graph *directed.Graph, opts *Options) error {
+	buf := bufio.NewWriter(out)
+	err := graph.DoVertices(func(vertex directed.Vertex) error {
+		field, err := opts.formatVertex(vertex)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(field)
+		err = graph.DoOutEdges(vertex, func(source, target directed.Vertex) error {
+			field, err := opts.formatVertex(target)
+			if err != nil {
+				return err
+			}
+			buf.WriteByte(' ')
+			buf.WriteString(field)
+			return nil
+		})
+		buf.WriteByte('\n')
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return buf.Flush()
+}
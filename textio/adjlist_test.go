@@ -0,0 +1,51 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package textio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAdjacencyList(t *testing.T) {
+	input := `# vertex followed by targets
+1 2 3
+2 3
+3
+4 1
+1 4
+`
+	graph, err := ReadAdjacencyList(strings.NewReader(input), &Options{ParseVertex: ParseInt})
+	if err != nil {
+		t.Fatalf("ReadAdjacencyList failed: %v", err)
+	}
+	if got := edgeString(graph); got != "1->2 1->3 1->4 2->3 4->1" {
+		t.Errorf("Wrong edges %s", got)
+	}
+
+	var out bytes.Buffer
+	if err := WriteAdjacencyList(&out, graph, nil); err != nil {
+		t.Fatalf("WriteAdjacencyList failed: %v", err)
+	}
+	expected := "1 2 3 4\n2 3\n3\n4 1\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+
+	undirected, err := ReadAdjacencyList(&out, &Options{Undirected: true})
+	if err != nil {
+		t.Fatalf("ReadAdjacencyList failed: %v", err)
+	}
+	if undirected.Order() != 4 || undirected.Size() != 8 {
+		t.Errorf("Expected 4 vertices and 8 edges, got %d and %d",
+			undirected.Order(), undirected.Size())
+	}
+
+	if _, err := ReadAdjacencyList(strings.NewReader("1 x"), &Options{ParseVertex: ParseInt}); err == nil {
+		t.Errorf("Expected error for bad vertex")
+	}
+}
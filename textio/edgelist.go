@@ -0,0 +1,134 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package textio
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// EdgeFunc is a function called for each edge read, with the weight
+// of the edge, or 1 if the edges are not weighted.
+type EdgeFunc func(source, target directed.Vertex, weight float64) error
+
+// ScanEdgeList will read an edge list from 'in' and call 'fn' for each
+// edge without building a graph. Each line of an edge list contains
+// the source and target of an edge, separated by white space, and a
+// weight if the options say that the edges are weighted. Any further
+// fields are ignored.
+func ScanEdgeList(in io.Reader, opts *Options, fn EdgeFunc) error {
+	ls := newLineScanner(in, opts.comments(), opts.maxLineLength())
+	weighted := opts != nil && opts.Weighted
+	for fields := ls.next(); fields != nil; fields = ls.next() {
+		if len(fields) < 2 {
+			return ls.errorf("expected source and target")
+		}
+		source, err := opts.parseVertex(fields[0])
+		if err != nil {
+			return ls.errorf("bad source %q: %v", fields[0], err)
+		}
+		target, err := opts.parseVertex(fields[1])
+		if err != nil {
+			return ls.errorf("bad target %q: %v", fields[1], err)
+		}
+		weight := 1.0
+		if weighted {
+			if len(fields) < 3 {
+				return ls.errorf("expected weight")
+			}
+			weight, err = strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return ls.errorf("bad weight %q", fields[2])
+			}
+		}
+		if err := fn(source, target, weight); err != nil {
+			return err
+		}
+	}
+	return ls.err()
+}
+
+// addEdge will return an edge function adding the edges to 'graph'
+// and, if 'weights' is not nil, recording the weights.
+func addEdge(graph *directed.Graph, weights directed.Weights, opts *Options) EdgeFunc {
+	undirected := opts != nil && opts.Undirected
+	return func(source, target directed.Vertex, weight float64) error {
+		graph.AddEdge(source, target)
+		if weights != nil {
+			weights[directed.Edge{Source: source, Target: target}] = weight
+		}
+		if undirected {
+			graph.AddEdge(target, source)
+			if weights != nil {
+				weights[directed.Edge{Source: target, Target: source}] = weight
+			}
+		}
+		return nil
+	}
+}
+
+// ReadEdgeList will read a graph given as an edge list from 'in'. If
+// the edges are weighted, the weights are also returned, otherwise
+// the returned weights are nil.
+func ReadEdgeList(in io.Reader, opts *Options) (*directed.Graph, directed.Weights, error) {
+	graph := directed.New()
+	var weights directed.Weights
+	if opts != nil && opts.Weighted {
+		weights = make(directed.Weights)
+	}
+	if err := ScanEdgeList(in, opts, addEdge(graph, weights, opts)); err != nil {
+		return nil, nil, err
+	}
+	return graph, weights, nil
+}
+
+// WriteEdgeList will write the edges of the graph to 'out' as an edge
+// list, one edge per line. If the options say that the graph is
+// weighted, the weight of each edge is written after the target.
+// Vertices without edges are not written.
+func WriteEdgeList(out io.Writer, graph *directed.Graph, opts *Options) error {
+	buf := bufio.NewWriter(out)
+	weight := weightFunc(opts)
+	err := graph.DoEdges(func(source, target directed.Vertex) error {
+		s, err := opts.formatVertex(source)
+		if err != nil {
+			return err
+		}
+		t, err := opts.formatVertex(target)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+		buf.WriteByte(' ')
+		buf.WriteString(t)
+		if weight != nil {
+			buf.WriteByte(' ')
+			buf.WriteString(strconv.FormatFloat(weight(source, target), 'g', -1, 64))
+		}
+		buf.WriteByte('\n')
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// weightFunc will return the weight function to use when writing, or
+// nil if the graph should be written without weights. If the graph is
+// weighted but there is no weight function, all weights are 1.
+func weightFunc(opts *Options) directed.WeightFunc {
+	if opts == nil || !opts.Weighted {
+		return nil
+	}
+	if opts.Weight == nil {
+		return func(source, target directed.Vertex) float64 { return 1 }
+	}
+	return opts.Weight
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package textio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func edgeString(graph *directed.Graph) string {
+	var edges []string
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		edges = append(edges, fmt.Sprintf("%v->%v", source, target))
+		return nil
+	})
+	return strings.Join(edges, " ")
+}
+
+func TestReadEdgeList(t *testing.T) {
+	input := `# Directed graph (each unordered pair of nodes is saved once)
+# FromNodeId	ToNodeId
+% KONECT style comment
+
+0	1
+1 2   extra
+  2	0
+`
+	graph, weights, err := ReadEdgeList(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("ReadEdgeList failed: %v", err)
+	}
+	if weights != nil {
+		t.Errorf("Expected no weights, got %v", weights)
+	}
+	if got := edgeString(graph); got != "0->1 1->2 2->0" {
+		t.Errorf("Wrong edges %s", got)
+	}
+	if !graph.HasEdge("0", "1") {
+		t.Errorf("Expected string vertices")
+	}
+
+	opts := &Options{Weighted: true, Undirected: true, ParseVertex: ParseInt, Comments: "c"}
+	graph, weights, err = ReadEdgeList(strings.NewReader("c comment\n1 2 0.5\n2 3 -1\n"), opts)
+	if err != nil {
+		t.Fatalf("ReadEdgeList failed: %v", err)
+	}
+	if got := edgeString(graph); got != "1->2 2->1 2->3 3->2" {
+		t.Errorf("Wrong edges %s", got)
+	}
+	if weights.Weight(2, 1) != 0.5 || weights.Weight(3, 2) != -1 || weights.Weight(1, 3) != 1 {
+		t.Errorf("Wrong weights %v", weights)
+	}
+
+	// Comment characters are runes, not bytes, so "ä" does not
+	// make lines starting with "å" comments.
+	opts = &Options{Comments: "ä"}
+	graph, _, err = ReadEdgeList(strings.NewReader("ä comment\nå b\n"), opts)
+	if err != nil {
+		t.Fatalf("ReadEdgeList failed: %v", err)
+	}
+	if got := edgeString(graph); got != "å->b" {
+		t.Errorf("Wrong edges %s", got)
+	}
+
+	// Lines longer than a few MiB are accepted by default.
+	long := strings.Repeat("v", 4<<20)
+	graph, _, err = ReadEdgeList(strings.NewReader("a "+long+"\n"), nil)
+	if err != nil || !graph.HasEdge("a", long) {
+		t.Errorf("Expected long line to be read, got %v", err)
+	}
+}
+
+func TestReadEdgeListErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  *Options
+		line  int
+	}{
+		{"a b\nc\n", nil, 2},
+		{"# comment\n1 x\n", &Options{ParseVertex: ParseInt}, 2},
+		{"a b\n\na b\n", &Options{Weighted: true}, 1},
+		{"a b 1\na b one\n", &Options{Weighted: true}, 2},
+		{"a b\n\nc " + strings.Repeat("d", 20) + "\n", &Options{MaxLineLength: 16}, 3},
+	}
+	for _, tt := range tests {
+		_, _, err := ReadEdgeList(strings.NewReader(tt.input), tt.opts)
+		prefix := fmt.Sprintf("textio: line %d:", tt.line)
+		if err == nil || !strings.HasPrefix(err.Error(), prefix) {
+			t.Errorf("Expected error on line %d for %q, got %v", tt.line, tt.input, err)
+		}
+	}
+}
+
+// edgeGenerator is a reader generating the edge list of a long path
+// one line at a time.
+type edgeGenerator struct {
+	next, last int
+	buf        bytes.Buffer
+}
+
+func (gen *edgeGenerator) Read(p []byte) (int, error) {
+	for gen.buf.Len() < len(p) && gen.next <= gen.last {
+		fmt.Fprintf(&gen.buf, "%d %d\n", gen.next, gen.next+1)
+		gen.next++
+	}
+	if gen.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return gen.buf.Read(p)
+}
+
+func TestScanEdgeList(t *testing.T) {
+	count := 0
+	gen := &edgeGenerator{last: 100000}
+	err := ScanEdgeList(gen, &Options{ParseVertex: ParseInt},
+		func(source, target directed.Vertex, weight float64) error {
+			if source != count || target != count+1 || weight != 1 {
+				return fmt.Errorf("unexpected edge %v -> %v", source, target)
+			}
+			count++
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("ScanEdgeList failed: %v", err)
+	}
+	if count != 100001 {
+		t.Errorf("Expected 100001 edges, got %d", count)
+	}
+}
+
+func TestWriteEdgeList(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+	graph.AddVertex("d")
+
+	var out bytes.Buffer
+	if err := WriteEdgeList(&out, graph, nil); err != nil {
+		t.Fatalf("WriteEdgeList failed: %v", err)
+	}
+	if out.String() != "a b\nb c\n" {
+		t.Errorf("Wrong output %q", out.String())
+	}
+
+	weights := directed.Weights{{Source: "a", Target: "b"}: 2.5}
+	out.Reset()
+	opts := &Options{Weighted: true, Weight: weights.Weight}
+	if err := WriteEdgeList(&out, graph, opts); err != nil {
+		t.Fatalf("WriteEdgeList failed: %v", err)
+	}
+	if out.String() != "a b 2.5\nb c 1\n" {
+		t.Errorf("Wrong output %q", out.String())
+	}
+
+	result, resultWeights, err := ReadEdgeList(&out, opts)
+	if err != nil {
+		t.Fatalf("ReadEdgeList failed: %v", err)
+	}
+	if edgeString(result) != edgeString(graph) || resultWeights.Weight("a", "b") != 2.5 {
+		t.Errorf("Round trip failed: %s %v", edgeString(result), resultWeights)
+	}
+
+	graph.AddEdge("with space", "a")
+	if err := WriteEdgeList(&out, graph, nil); err == nil {
+		t.Errorf("Expected error for vertex with space")
+	}
+}
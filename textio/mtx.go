@@ -0,0 +1,174 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package textio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// mtxHeader is the start of the header line of a Matrix Market file.
+const mtxHeader = "%%MatrixMarket"
+
+// ReadMatrixMarket will read a graph given as a sparse adjacency
+// matrix in the coordinate format of Matrix Market from 'in'. The
+// matrix has to be square, and each entry in row i and column j is an
+// edge from vertex i to vertex j. The vertices are the indexes as
+// ints, starting at 1, unless ParseVertex is given, in which case it
+// is called with each index.
+//
+// Matrices with real or integer values are weighted, and the weights
+// are returned, while pattern matrices are not weighted and the
+// returned weights are nil. For symmetric and skew-symmetric matrices,
+// the edges of the entries that are not stored are added as well.
+func ReadMatrixMarket(in io.Reader, opts *Options) (*directed.Graph, directed.Weights, error) {
+	ls := newLineScanner(in, "%", opts.maxLineLength())
+	if !ls.scanner.Scan() {
+		if err := ls.err(); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("textio: empty Matrix Market file")
+	}
+	ls.line++
+	header := strings.Fields(strings.ToLower(ls.scanner.Text()))
+	if len(header) != 5 || header[0] != strings.ToLower(mtxHeader) || header[1] != "matrix" {
+		return nil, nil, ls.errorf("expected Matrix Market header")
+	}
+	if header[2] != "coordinate" {
+		return nil, nil, ls.errorf("unsupported format %q", header[2])
+	}
+	field, symmetry := header[3], header[4]
+	switch field {
+	case "real", "double", "integer", "pattern":
+	default:
+		return nil, nil, ls.errorf("unsupported field %q", field)
+	}
+	switch symmetry {
+	case "general", "symmetric", "skew-symmetric", "hermitian":
+	default:
+		return nil, nil, ls.errorf("unsupported symmetry %q", symmetry)
+	}
+
+	size := ls.next()
+	if size == nil {
+		return nil, nil, ls.errorf("expected size line")
+	}
+	var dims [3]int
+	if len(size) != 3 {
+		return nil, nil, ls.errorf("expected rows, columns, and entries")
+	}
+	for i, text := range size {
+		value, err := strconv.Atoi(text)
+		if err != nil || value < 0 {
+			return nil, nil, ls.errorf("bad size %q", text)
+		}
+		dims[i] = value
+	}
+	order, entries := dims[0], dims[2]
+	if dims[0] != dims[1] {
+		return nil, nil, ls.errorf("matrix is not square")
+	}
+
+	graph := directed.New()
+	vertices := make([]directed.Vertex, order+1)
+	for i := 1; i <= order; i++ {
+		vertices[i] = i
+		if opts != nil && opts.ParseVertex != nil {
+			vertex, err := opts.ParseVertex(strconv.Itoa(i))
+			if err != nil {
+				return nil, nil, fmt.Errorf("textio: bad vertex %d: %v", i, err)
+			}
+			vertices[i] = vertex
+		}
+		graph.AddVertex(vertices[i])
+	}
+
+	var weights directed.Weights
+	if field != "pattern" {
+		weights = make(directed.Weights)
+	}
+	add := addEdge(graph, weights, opts)
+	count := 0
+	for fields := ls.next(); fields != nil; fields = ls.next() {
+		count++
+		if count > entries {
+			return nil, nil, ls.errorf("more than %d entries", entries)
+		}
+		if field == "pattern" && len(fields) < 2 || field != "pattern" && len(fields) < 3 {
+			return nil, nil, ls.errorf("too few fields")
+		}
+		var index [2]int
+		for k := range index {
+			value, err := strconv.Atoi(fields[k])
+			if err != nil || value < 1 || value > order {
+				return nil, nil, ls.errorf("bad index %q", fields[k])
+			}
+			index[k] = value
+		}
+		weight := 1.0
+		if field != "pattern" {
+			var err error
+			weight, err = strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return nil, nil, ls.errorf("bad value %q", fields[2])
+			}
+		}
+		source, target := vertices[index[0]], vertices[index[1]]
+		add(source, target, weight)
+		if index[0] != index[1] {
+			switch symmetry {
+			case "symmetric", "hermitian":
+				add(target, source, weight)
+			case "skew-symmetric":
+				add(target, source, -weight)
+			}
+		}
+	}
+	if err := ls.err(); err != nil {
+		return nil, nil, err
+	}
+	if count != entries {
+		return nil, nil, fmt.Errorf("textio: expected %d entries, found %d", entries, count)
+	}
+	return graph, weights, nil
+}
+
+// WriteMatrixMarket will write the graph to 'out' as a sparse
+// adjacency matrix in the coordinate format of Matrix Market. The
+// vertices are numbered from 1 in the order they were added to the
+// graph. If the options say that the graph is weighted, a real matrix
+// with the weights is written, otherwise a pattern matrix.
+func WriteMatrixMarket(out io.Writer, graph *directed.Graph, opts *Options) error {
+	buf := bufio.NewWriter(out)
+	weight := weightFunc(opts)
+	field := "pattern"
+	if weight != nil {
+		field = "real"
+	}
+	fmt.Fprintf(buf, "%s matrix coordinate %s general\n", mtxHeader, field)
+	fmt.Fprintf(buf, "%d %d %d\n", graph.Order(), graph.Order(), graph.Size())
+
+	index := make(map[directed.Vertex]int, graph.Order())
+	graph.DoVertices(func(vertex directed.Vertex) error {
+		index[vertex] = len(index) + 1
+		return nil
+	})
+	graph.DoEdges(func(source, target directed.Vertex) error {
+		fmt.Fprintf(buf, "%d %d", index[source], index[target])
+		if weight != nil {
+			buf.WriteByte(' ')
+			buf.WriteString(strconv.FormatFloat(weight(source, target), 'g', -1, 64))
+		}
+		buf.WriteByte('\n')
+		return nil
+	})
+	return buf.Flush()
+}
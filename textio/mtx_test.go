@@ -0,0 +1,105 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package textio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+func TestReadMatrixMarket(t *testing.T) {
+	input := `%%MatrixMarket matrix coordinate real symmetric
+% A symmetric matrix where only the lower triangle is stored.
+%
+4 4 4
+1 1 2.0
+2 1 -1.5
+3 2 0.5
+4 3 1e2
+`
+	graph, weights, err := ReadMatrixMarket(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("ReadMatrixMarket failed: %v", err)
+	}
+	if got := edgeString(graph); got != "1->1 1->2 2->1 2->3 3->2 3->4 4->3" {
+		t.Errorf("Wrong edges %s", got)
+	}
+	if weights.Weight(1, 2) != -1.5 || weights.Weight(4, 3) != 100 {
+		t.Errorf("Wrong weights %v", weights)
+	}
+
+	input = `%%MatrixMarket matrix coordinate pattern skew-symmetric
+3 3 1
+2 1
+`
+	graph, weights, err = ReadMatrixMarket(strings.NewReader(input), &Options{
+		ParseVertex: func(field string) (directed.Vertex, error) {
+			return "v" + field, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ReadMatrixMarket failed: %v", err)
+	}
+	if weights != nil || graph.Order() != 3 || edgeString(graph) != "v1->v2 v2->v1" {
+		t.Errorf("Wrong graph %s with weights %v", edgeString(graph), weights)
+	}
+}
+
+func TestReadMatrixMarketErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"1 2\n",
+		"%%MatrixMarket matrix array real general\n2 2\n1\n2\n3\n4\n",
+		"%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n",
+		"%%MatrixMarket matrix coordinate real general\n2 3 0\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 3 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 2\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 2 1\n",
+		"%%MatrixMarket matrix coordinate pattern general\n2 2 1\n1 2\n2 1\n",
+	}
+	for _, input := range tests {
+		if _, _, err := ReadMatrixMarket(strings.NewReader(input), nil); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestWriteMatrixMarket(t *testing.T) {
+	graph := directed.New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+	graph.AddEdge("c", "a")
+
+	var out bytes.Buffer
+	weights := directed.Weights{
+		{Source: "a", Target: "b"}: 0.5,
+		{Source: "c", Target: "a"}: 3,
+	}
+	opts := &Options{Weighted: true, Weight: weights.Weight}
+	if err := WriteMatrixMarket(&out, graph, opts); err != nil {
+		t.Fatalf("WriteMatrixMarket failed: %v", err)
+	}
+	expected := `%%MatrixMarket matrix coordinate real general
+3 3 3
+1 2 0.5
+2 3 1
+3 1 3
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	result, resultWeights, err := ReadMatrixMarket(&out, nil)
+	if err != nil {
+		t.Fatalf("ReadMatrixMarket failed: %v", err)
+	}
+	if edgeString(result) != "1->2 2->3 3->1" || resultWeights.Weight(1, 2) != 0.5 {
+		t.Errorf("Wrong graph %s with weights %v", edgeString(result), resultWeights)
+	}
+}
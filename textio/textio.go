@@ -0,0 +1,160 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package textio implements reading and writing of graphs in simple
+// line-based text formats: edge lists, adjacency lists, and the
+// coordinate format of Matrix Market. These are the formats used by
+// benchmark collections such as SNAP and SuiteSparse.
+//
+// All readers process the input one line at a time, so very large
+// files can be read without holding the file in memory.
+package textio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mkindahl/gograph/directed"
+)
+
+// DefaultMaxLineLength is the longest line accepted by the readers,
+// in bytes, unless the options say otherwise. It is large enough for
+// the adjacency lists of hub vertices in large graphs.
+const DefaultMaxLineLength = 64 << 20
+
+// Options control how graphs are read and written. A nil *Options is
+// the same as the zero value.
+type Options struct {
+	// Comments are the characters that start a comment line. If
+	// empty, lines starting with '#' or '%' are comments.
+	Comments string
+
+	// Weighted will, if true, read a weight for each edge of an
+	// edge list, or write the weight given by Weight.
+	Weighted bool
+
+	// Undirected will, if true, add each edge read in both
+	// directions.
+	Undirected bool
+
+	// ParseVertex is called to convert each vertex field to a
+	// vertex. If nil, the vertices are the fields as strings, except
+	// for Matrix Market where they are the indexes as ints.
+	ParseVertex func(field string) (directed.Vertex, error)
+
+	// FormatVertex is called to convert each vertex to a field
+	// when writing. If nil, the string representation of the vertex
+	// is used.
+	FormatVertex func(vertex directed.Vertex) string
+
+	// Weight is called to get the weight of each edge when writing
+	// a weighted graph.
+	Weight directed.WeightFunc
+
+	// MaxLineLength is the longest line accepted when reading, in
+	// bytes. If zero, DefaultMaxLineLength is used.
+	MaxLineLength int
+}
+
+// ParseInt can be used as ParseVertex to read vertices as ints.
+func ParseInt(field string) (directed.Vertex, error) {
+	return strconv.Atoi(field)
+}
+
+func (opts *Options) comments() string {
+	if opts == nil || opts.Comments == "" {
+		return "#%"
+	}
+	return opts.Comments
+}
+
+func (opts *Options) maxLineLength() int {
+	if opts == nil || opts.MaxLineLength <= 0 {
+		return DefaultMaxLineLength
+	}
+	return opts.MaxLineLength
+}
+
+func (opts *Options) parseVertex(field string) (directed.Vertex, error) {
+	if opts == nil || opts.ParseVertex == nil {
+		return field, nil
+	}
+	return opts.ParseVertex(field)
+}
+
+// formatVertex will convert a vertex to a field, checking that it
+// does not contain white space, since that would make the output
+// impossible to read back.
+func (opts *Options) formatVertex(vertex directed.Vertex) (string, error) {
+	var field string
+	if opts == nil || opts.FormatVertex == nil {
+		field = fmt.Sprint(vertex)
+	} else {
+		field = opts.FormatVertex(vertex)
+	}
+	if field == "" || strings.IndexFunc(field, isSpace) >= 0 {
+		return "", fmt.Errorf("textio: vertex %#v cannot be written as %q", vertex, field)
+	}
+	return field, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\v' || r == '\f'
+}
+
+// lineScanner reads the input one line at a time, skipping empty
+// lines and comments and keeping track of the line number.
+type lineScanner struct {
+	scanner  *bufio.Scanner
+	comments string
+	line     int
+}
+
+func newLineScanner(in io.Reader, comments string, maxLength int) *lineScanner {
+	size := 64 * 1024
+	if maxLength < size {
+		size = maxLength
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, size), maxLength)
+	return &lineScanner{scanner: scanner, comments: comments}
+}
+
+// next will return the fields of the next line that is not empty and
+// not a comment, or nil at the end of the input.
+func (ls *lineScanner) next() []string {
+	for ls.scanner.Scan() {
+		ls.line++
+		text := strings.TrimLeftFunc(ls.scanner.Text(), isSpace)
+		if text == "" {
+			continue
+		}
+		if first, _ := utf8.DecodeRuneInString(text); strings.ContainsRune(ls.comments, first) {
+			continue
+		}
+		return strings.FieldsFunc(text, isSpace)
+	}
+	return nil
+}
+
+// err will return any error from reading the input. If a line is too
+// long, the error gives the number of the line.
+func (ls *lineScanner) err() error {
+	err := ls.scanner.Err()
+	if errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("textio: line %d: %w", ls.line+1, err)
+	}
+	return err
+}
+
+// errorf will return an error for the current line.
+func (ls *lineScanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("textio: line %d: %s", ls.line, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import "container/list"
+
+type vertexPair struct {
+	parent Vertex
+	child  Vertex
+}
+
+// breadthFirstVisit will perform a breadth-first walk starting with a
+// single vertex and report the events to the walker.
+//
+// Each non-tree edge is reported once as a cross edge, when it is
+// examined from the first of its endpoints to be processed.
+func (graph *Graph) breadthFirstVisit(walker Walker, seen map[Vertex]uint8, vertex Vertex) error {
+	queue := list.New()
+
+	seen[vertex] = GREY
+	if err := walker.OnDiscover(nil, vertex); err != nil {
+		return err
+	}
+	queue.PushBack(&vertexPair{parent: nil, child: vertex})
+
+	for queue.Len() != 0 {
+		pair := queue.Remove(queue.Front()).(*vertexPair)
+		err := graph.DoIncidentEdges(pair.child, func(from, to Vertex) error {
+			switch seen[to] {
+			case WHITE:
+				seen[to] = GREY
+				if err := walker.OnDiscover(from, to); err != nil {
+					return err
+				}
+				queue.PushBack(&vertexPair{parent: from, child: to})
+			case GREY:
+				// Vertices that are BLACK have already
+				// examined the edge, so only edges to
+				// vertices still in the queue are new.
+				return walker.OnCrossEdge(from, to)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		seen[pair.child] = BLACK
+		if err := walker.OnFinish(pair.parent, pair.child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BreadthFirstWalkFromVertex uses the passed walker to traverse the
+// graph breadth-first. The search starts at the given vertex.
+// Vertices with no path to the given vertex will NOT be discovered.
+func (graph *Graph) BreadthFirstWalkFromVertex(walker Walker, vertex Vertex) error {
+	seen := make(map[Vertex]uint8)
+	return graph.breadthFirstVisit(walker, seen, vertex)
+}
+
+// DoBreadthFirstWalkFromVertex performs a breadth-first search
+// starting at the given vertex, calling the onDiscover function when
+// a new vertex is discovered and the onFinish function when a vertex
+// has been traversed. Either function can be nil.
+func (graph *Graph) DoBreadthFirstWalkFromVertex(startAt Vertex, onDiscover, onFinish VertexWalkFunc) error {
+	walker := &fillableWalker{
+		onDiscover: onDiscover,
+		onFinish:   onFinish,
+	}
+	return graph.BreadthFirstWalkFromVertex(walker, startAt)
+}
+
+// BreadthFirstWalk uses the provided walker to perform a
+// breadth-first search over the entire graph, starting at the first
+// vertex added, and completing after all vertices in the graph are
+// traversed.
+func (graph *Graph) BreadthFirstWalk(walker Walker) error {
+	seen := make(map[Vertex]uint8)
+	return graph.DoVertices(func(vertex Vertex) error {
+		if seen[vertex] != WHITE {
+			return nil
+		}
+		return graph.breadthFirstVisit(walker, seen, vertex)
+	})
+}
+
+// DoBreadthFirstWalk performs a breadth-first search walk over the
+// entire graph, starting at the first vertex added and completing
+// after all vertices in the graph are traversed.
+func (graph *Graph) DoBreadthFirstWalk(onDiscover, onFinish VertexWalkFunc) error {
+	walker := &fillableWalker{
+		onDiscover: onDiscover,
+		onFinish:   onFinish,
+	}
+	return graph.BreadthFirstWalk(walker)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import "testing"
+
+func TestBreadthFirstWalkFromVertex(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("a", "c")
+	graph.AddEdge("b", "d")
+	graph.AddEdge("c", "d")
+	graph.AddEdge("d", "e")
+	graph.AddEdge("1", "2")
+
+	info := make(map[Vertex]int)
+	fin := 0
+	graph.DoBreadthFirstWalkFromVertex("e", nil, func(vertex Vertex) error {
+		info[vertex] = fin
+		fin++
+		return nil
+	})
+	if len(info) != 5 {
+		t.Errorf("Wrong number of vertices processed: %v", info)
+	}
+	if !(info["e"] < info["d"] && info["d"] < info["b"] && info["d"] < info["c"] &&
+		info["b"] < info["a"] && info["c"] < info["a"]) {
+		t.Errorf("Incorrect ordering: %v", info)
+	}
+
+	walker := &edgeWalker{}
+	graph.BreadthFirstWalkFromVertex(walker, "a")
+	if walker.tree != 4 || walker.cross != 1 || walker.back != 0 {
+		t.Errorf("Wrong edge classification (tree %d, back %d, cross %d)",
+			walker.tree, walker.back, walker.cross)
+	}
+}
+
+func TestBreadthFirstWalk(t *testing.T) {
+	graph := New()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "c")
+	graph.AddEdge("c", "a")
+	graph.AddEdge("1", "2")
+
+	count := make(map[Vertex]int)
+	graph.DoBreadthFirstWalk(func(vertex Vertex) error {
+		count[vertex]++
+		return nil
+	}, nil)
+	if len(count) != 5 {
+		t.Errorf("Could not find all vertices in breadth first walk: %v", count)
+	}
+	for vertex, n := range count {
+		if n != 1 {
+			t.Errorf("Vertex %v discovered %d times", vertex, n)
+		}
+	}
+}
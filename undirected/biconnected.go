@@ -0,0 +1,186 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import "github.com/mkindahl/gograph/internal/lowlink"
+
+// biconnectedWalker is used to discover the articulation points,
+// bridges, and biconnected components of a graph using the algorithm
+// by Hopcroft and Tarjan. The low-link of a vertex is the lowest
+// discovery number reachable from the subtree rooted at the vertex
+// using at most one back edge. Any of the callbacks can be nil.
+type biconnectedWalker struct {
+	DefaultWalker
+	root     Vertex
+	links    *lowlink.Table
+	children map[Vertex]int
+	reported map[Vertex]bool
+	stack    [][2]Vertex
+
+	onArticulation VertexWalkFunc
+	onBridge       EdgeWalkFunc
+	onComponent    GraphWalkFunc
+}
+
+func newBiconnectedWalker() *biconnectedWalker {
+	return &biconnectedWalker{
+		links:    lowlink.New(),
+		children: make(map[Vertex]int),
+		reported: make(map[Vertex]bool),
+	}
+}
+
+// articulation will report 'vertex' as an articulation point unless
+// it has already been reported.
+func (walker *biconnectedWalker) articulation(vertex Vertex) error {
+	if walker.reported[vertex] {
+		return nil
+	}
+	walker.reported[vertex] = true
+	if walker.onArticulation != nil {
+		return walker.onArticulation(vertex)
+	}
+	return nil
+}
+
+// popComponent will pop all edges down to and including the tree
+// edge from 'parent' to 'vertex' from the edge stack. These edges
+// form a biconnected component.
+func (walker *biconnectedWalker) popComponent(parent, vertex Vertex) error {
+	component := New()
+	for {
+		edge := walker.stack[len(walker.stack)-1]
+		walker.stack = walker.stack[:len(walker.stack)-1]
+		component.AddEdge(edge[0], edge[1])
+		if edge[0] == parent && edge[1] == vertex {
+			break
+		}
+	}
+	if walker.onComponent != nil {
+		return walker.onComponent(component)
+	}
+	return nil
+}
+
+func (walker *biconnectedWalker) OnDiscover(parent, vertex Vertex) error {
+	walker.links.Discover(vertex)
+	if parent == nil {
+		walker.root = vertex
+	} else {
+		walker.children[parent]++
+		walker.stack = append(walker.stack, [2]Vertex{parent, vertex})
+	}
+	return nil
+}
+
+func (walker *biconnectedWalker) OnBackEdge(source, target Vertex) error {
+	// Self-loops do not affect the connectivity of the graph.
+	if source == target {
+		return nil
+	}
+	walker.links.Lower(source, walker.links.Number(target))
+	walker.stack = append(walker.stack, [2]Vertex{source, target})
+	return nil
+}
+
+func (walker *biconnectedWalker) OnFinish(parent, vertex Vertex) error {
+	if parent == nil {
+		// The root is an articulation point if it has more
+		// than one child in the depth-first tree.
+		if walker.children[vertex] > 1 {
+			return walker.articulation(vertex)
+		}
+		return nil
+	}
+
+	walker.links.Finish(parent, vertex)
+	low, number := walker.links.Low(vertex), walker.links.Number(parent)
+
+	// If there is no back edge from the subtree of the vertex to
+	// a proper ancestor of the parent, the parent separates the
+	// subtree from the rest of the graph.
+	if low >= number {
+		if parent != walker.root {
+			if err := walker.articulation(parent); err != nil {
+				return err
+			}
+		}
+		if err := walker.popComponent(parent, vertex); err != nil {
+			return err
+		}
+	}
+
+	// If there is no back edge from the subtree of the vertex to
+	// the parent or above, the tree edge is a bridge.
+	if low > number && walker.onBridge != nil {
+		return walker.onBridge(parent, vertex)
+	}
+	return nil
+}
+
+// DoArticulationPoints will call 'onVertex' for each articulation
+// point (cut vertex) of the graph, that is, each vertex whose removal
+// increases the number of connected components of the graph. If the
+// function returns an error, iteration will be aborted and the error
+// returned.
+func (graph *Graph) DoArticulationPoints(onVertex VertexWalkFunc) error {
+	walker := newBiconnectedWalker()
+	walker.onArticulation = onVertex
+	return graph.DepthFirstWalk(walker)
+}
+
+// DoBridges will call 'onBridge' for each bridge of the graph, that
+// is, each edge whose removal increases the number of connected
+// components of the graph. If the function returns an error,
+// iteration will be aborted and the error returned.
+func (graph *Graph) DoBridges(onBridge EdgeWalkFunc) error {
+	walker := newBiconnectedWalker()
+	walker.onBridge = onBridge
+	return graph.DepthFirstWalk(walker)
+}
+
+// DoBiconnectedComponents will call 'onComponent' for each biconnected
+// component (block) of the graph, that is, each maximal subgraph that
+// remains connected if any single vertex is removed. Each edge of the
+// graph, except self-loops, belong to exactly one component, while
+// articulation points belong to several components. Vertices without
+// any edges are not part of any component. If the function returns
+// an error, iteration will be aborted and the error returned.
+func (graph *Graph) DoBiconnectedComponents(onComponent GraphWalkFunc) error {
+	walker := newBiconnectedWalker()
+	walker.onComponent = onComponent
+	return graph.DepthFirstWalk(walker)
+}
+
+// BlockCutTree will compute the block-cut tree of the graph. The
+// vertices of the block-cut tree are the biconnected components of
+// the graph, given as '*Graph' values, and the articulation points of
+// the graph. There is an edge between a component and each
+// articulation point contained in the component.
+//
+// If the graph is not connected, the result is a forest with one tree
+// for each connected component having at least one edge.
+func (graph *Graph) BlockCutTree() *Graph {
+	var blocks []*Graph
+	walker := newBiconnectedWalker()
+	walker.onComponent = func(block *Graph) error {
+		blocks = append(blocks, block)
+		return nil
+	}
+	graph.DepthFirstWalk(walker)
+
+	tree := New()
+	for _, block := range blocks {
+		tree.AddVertex(block)
+		block.DoVertices(func(vertex Vertex) error {
+			if walker.reported[vertex] {
+				tree.AddEdge(block, vertex)
+			}
+			return nil
+		})
+	}
+	return tree
+}
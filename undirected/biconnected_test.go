@@ -0,0 +1,78 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import "testing"
+
+// Two triangles connected by a bridge, with a pendant edge and a
+// self-loop attached to the second triangle, and an isolated vertex.
+func biconnectedExample() *Graph {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(3, 1)
+	graph.AddEdge(3, 4)
+	graph.AddEdge(4, 5)
+	graph.AddEdge(5, 6)
+	graph.AddEdge(6, 4)
+	graph.AddEdge(6, 7)
+	graph.AddEdge(5, 5)
+	graph.AddVertex(8)
+	return graph
+}
+
+func TestArticulationPoints(t *testing.T) {
+	graph := biconnectedExample()
+	found := make(map[Vertex]int)
+	graph.DoArticulationPoints(func(vertex Vertex) error {
+		found[vertex]++
+		return nil
+	})
+	if len(found) != 3 || found[3] != 1 || found[4] != 1 || found[6] != 1 {
+		t.Errorf("Wrong articulation points: %v", found)
+	}
+}
+
+func TestBridges(t *testing.T) {
+	graph := biconnectedExample()
+	found := make(map[[2]Vertex]bool)
+	graph.DoBridges(func(x, y Vertex) error {
+		if x.(int) > y.(int) {
+			x, y = y, x
+		}
+		found[[2]Vertex{x, y}] = true
+		return nil
+	})
+	if len(found) != 2 || !found[[2]Vertex{3, 4}] || !found[[2]Vertex{6, 7}] {
+		t.Errorf("Wrong bridges: %v", found)
+	}
+}
+
+func TestBiconnectedComponents(t *testing.T) {
+	graph := biconnectedExample()
+	edges := 0
+	sizes := make(map[int]int)
+	graph.DoBiconnectedComponents(func(block *Graph) error {
+		sizes[block.Order()]++
+		edges += block.Size()
+		return nil
+	})
+	if sizes[2] != 2 || sizes[3] != 2 || len(sizes) != 2 {
+		t.Errorf("Wrong component sizes: %v", sizes)
+	}
+	if edges != graph.Size()-1 {
+		t.Errorf("Wrong number of edges in components (was %d, expected %d)",
+			edges, graph.Size()-1)
+	}
+
+	tree := graph.BlockCutTree()
+	checkGraphCount(t, tree, 7, 6)
+	for _, cut := range []int{3, 4, 6} {
+		if tree.Degree(cut) != 2 {
+			t.Errorf("Cut vertex %v has degree %d", cut, tree.Degree(cut))
+		}
+	}
+}
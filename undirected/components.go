@@ -0,0 +1,134 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import "github.com/mkindahl/gograph/djs"
+
+// GraphWalkFunc is a function called on subgraphs of a graph, for
+// example, when iterating over the connected components of a graph.
+type GraphWalkFunc func(graph *Graph) error
+
+// Connectivity keeps track of the connected components of a set of
+// vertices as edges are added between them. It is built on top of a
+// disjoint-set structure, so each operation is done in nearly
+// constant time, but edges cannot be removed.
+//
+// The structure is not tied to a graph, so it can be used to track
+// connectivity of any graph, for example, the weakly connected
+// components of a directed graph.
+type Connectivity struct {
+	sets     *djs.DisjointSet
+	vertices []Vertex
+	count    int
+}
+
+// NewConnectivity will create a new connectivity structure without
+// any vertices.
+func NewConnectivity() *Connectivity {
+	return &Connectivity{sets: djs.New()}
+}
+
+// AddVertex will add a vertex as a component by itself. The function
+// return 'true' if the vertex was added, and 'false' if the vertex
+// already existed.
+func (conn *Connectivity) AddVertex(vertex Vertex) bool {
+	if conn.sets.Contains(vertex) {
+		return false
+	}
+	conn.sets.MakeSet(vertex)
+	conn.vertices = append(conn.vertices, vertex)
+	conn.count++
+	return true
+}
+
+// AddEdge will add an edge between two vertices, merging their
+// components. The vertices are added if they are not already
+// present. The function return 'true' if two components were merged,
+// and 'false' if the vertices already were in the same component.
+func (conn *Connectivity) AddEdge(x, y Vertex) bool {
+	conn.AddVertex(x)
+	conn.AddVertex(y)
+	if conn.sets.Find(x) == conn.sets.Find(y) {
+		return false
+	}
+	conn.sets.Union(x, y)
+	conn.count--
+	return true
+}
+
+// SameComponent check if two vertices are in the same component. Will
+// return 'false' if either vertex is missing.
+func (conn *Connectivity) SameComponent(x, y Vertex) bool {
+	if !conn.sets.Contains(x) || !conn.sets.Contains(y) {
+		return false
+	}
+	return conn.sets.Find(x) == conn.sets.Find(y)
+}
+
+// Count will return the number of components.
+func (conn *Connectivity) Count() int {
+	return conn.count
+}
+
+// DoComponents will call 'walkFn' once for each component with the
+// vertices of the component. The vertices of each component are in
+// the order they were added. If the walk function returns an error,
+// iteration will be aborted and the error returned.
+func (conn *Connectivity) DoComponents(walkFn func(vertices []Vertex) error) error {
+	members := make(map[*djs.Node][]Vertex, conn.count)
+	var order []*djs.Node
+	for _, vertex := range conn.vertices {
+		root := conn.sets.Find(vertex)
+		if members[root] == nil {
+			order = append(order, root)
+		}
+		members[root] = append(members[root], vertex)
+	}
+	for _, root := range order {
+		if err := walkFn(members[root]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Connectivity will return a connectivity structure for the graph,
+// which can be used to query the connected components of the graph,
+// and to track how they change as more edges are added.
+func (graph *Graph) Connectivity() *Connectivity {
+	conn := NewConnectivity()
+	graph.DoVertices(func(vertex Vertex) error {
+		conn.AddVertex(vertex)
+		return nil
+	})
+	graph.DoEdges(func(x, y Vertex) error {
+		conn.AddEdge(x, y)
+		return nil
+	})
+	return conn
+}
+
+// DoComponents will call the onComponent function for each connected
+// component of the graph, including components consisting of a single
+// vertex. If the function returns an error, iteration will be
+// aborted and the error returned.
+func (graph *Graph) DoComponents(onComponent GraphWalkFunc) error {
+	return graph.Connectivity().DoComponents(func(vertices []Vertex) error {
+		component := New()
+		for _, vertex := range vertices {
+			component.AddVertex(vertex)
+		}
+		// Since the component is connected, all edges of the
+		// vertices are inside the component.
+		for _, vertex := range vertices {
+			graph.DoIncidentEdges(vertex, func(x, y Vertex) error {
+				component.AddEdge(x, y)
+				return nil
+			})
+		}
+		return onComponent(component)
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import "testing"
+
+func TestConnectivity(t *testing.T) {
+	conn := NewConnectivity()
+	checkCount := func(expected int) {
+		if count := conn.Count(); count != expected {
+			t.Errorf("Wrong number of components (was %d, expected %d)", count, expected)
+		}
+	}
+
+	for i := 1; i <= 6; i++ {
+		conn.AddVertex(i)
+	}
+	checkCount(6)
+	if conn.SameComponent(1, 2) {
+		t.Errorf("%v and %v in same component", 1, 2)
+	}
+
+	if !conn.AddEdge(1, 2) || !conn.AddEdge(2, 3) {
+		t.Errorf("Components not merged")
+	}
+	if conn.AddEdge(3, 1) {
+		t.Errorf("Same component merged")
+	}
+	checkCount(4)
+	if !conn.SameComponent(1, 3) {
+		t.Errorf("%v and %v not in same component", 1, 3)
+	}
+
+	conn.AddEdge(4, 5)
+	conn.AddEdge(7, 8)
+	checkCount(4)
+	if conn.SameComponent(3, 4) || conn.SameComponent(1, 9) {
+		t.Errorf("Vertices in same component")
+	}
+
+	sizes := make(map[int]int)
+	conn.DoComponents(func(vertices []Vertex) error {
+		sizes[len(vertices)]++
+		return nil
+	})
+	if sizes[1] != 1 || sizes[2] != 2 || sizes[3] != 1 {
+		t.Errorf("Wrong component sizes: %v", sizes)
+	}
+}
+
+func TestDoComponents(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(3, 1)
+	graph.AddEdge(4, 5)
+	graph.AddVertex(6)
+
+	count := 0
+	graph.DoComponents(func(component *Graph) error {
+		count++
+		switch {
+		case component.HasVertex(1):
+			checkGraphCount(t, component, 3, 3)
+		case component.HasVertex(4):
+			checkGraphCount(t, component, 2, 1)
+		default:
+			checkGraphCount(t, component, 1, 0)
+		}
+		return nil
+	})
+	if count != 3 {
+		t.Errorf("Wrong number of components (was %d, expected %d)", count, 3)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import "container/list"
+
+// Walker interface is used by the depth-first and breadth-first visit
+// functions. All the methods have to be implemented. To help with
+// implementing default methods (that do nothing) please embed the
+// DefaultWalker.
+//
+// In an undirected graph, a depth-first walk only has tree edges and
+// back edges, so OnCrossEdge is only called by the breadth-first
+// walks.
+type Walker interface {
+	OnDiscover(parent, vertex Vertex) error
+	OnFinish(parent, vertex Vertex) error
+	OnBackEdge(source, target Vertex) error
+	OnCrossEdge(source, target Vertex) error
+}
+
+// DefaultWalker implement default methods for use when implementing a
+// walker. The default methods do nothing.
+type DefaultWalker struct{}
+
+// OnDiscover implement the default callback for node discovery
+func (walker *DefaultWalker) OnDiscover(parent, vertex Vertex) error {
+	return nil
+}
+
+// OnFinish implement the default callback for completing nodes
+func (walker *DefaultWalker) OnFinish(parent, vertex Vertex) error {
+	return nil
+}
+
+// OnBackEdge implement the default callback for discovering back edges
+func (walker *DefaultWalker) OnBackEdge(source, target Vertex) error {
+	return nil
+}
+
+// OnCrossEdge implement the default callback for discovering cross edges
+func (walker *DefaultWalker) OnCrossEdge(source, target Vertex) error {
+	return nil
+}
+
+const (
+	WHITE = iota // Undiscovered
+	GREY         // Discovered, but not finalized
+	BLACK        // Finalized
+)
+
+// dfsFrame is an entry on the explicit stack used by the depth-first
+// walk. It holds a vertex that has been discovered but not finished,
+// and the next incident edge of the vertex to follow.
+type dfsFrame struct {
+	parent, vertex Vertex
+	next           *list.Element
+}
+
+// depthFirstVisit will perform a depth-first walk starting with a
+// single vertex and report the events to the walker. The walk uses an
+// explicit stack rather than recursion, so the depth of the walk is
+// not limited by the size of the goroutine stack.
+//
+// Each non-tree edge is reported once as a back edge, from the
+// descendant to the ancestor. The edge to the parent is the tree edge
+// and is not reported as a back edge.
+func (graph *Graph) depthFirstVisit(walker Walker, info map[Vertex]uint8, root Vertex) error {
+	var stack []dfsFrame
+	discover := func(parent, vertex Vertex) error {
+		info[vertex] = GREY
+		if err := walker.OnDiscover(parent, vertex); err != nil {
+			return err
+		}
+		stack = append(stack, dfsFrame{parent, vertex, graph.edges[vertex].Front()})
+		return nil
+	}
+
+	if err := discover(nil, root); err != nil {
+		return err
+	}
+	for len(stack) > 0 {
+		frame := &stack[len(stack)-1]
+		if frame.next == nil {
+			parent, vertex := frame.parent, frame.vertex
+			stack = stack[:len(stack)-1]
+			info[vertex] = BLACK
+			if err := walker.OnFinish(parent, vertex); err != nil {
+				return err
+			}
+			continue
+		}
+
+		source, target := frame.vertex, frame.next.Value
+		frame.next = frame.next.Next()
+		switch info[target] {
+		case WHITE:
+			if err := discover(source, target); err != nil {
+				return err
+			}
+		case GREY:
+			if target != frame.parent {
+				if err := walker.OnBackEdge(source, target); err != nil {
+					return err
+				}
+			}
+		}
+		// Edges to BLACK vertices were already reported as back
+		// edges from the other endpoint.
+	}
+	return nil
+}
+
+// DepthFirstWalk will perform a depth-first walk over the entire
+// graph, reporting the events to the walker. The roots of the walk
+// are picked in the order the vertices were added to the graph. If a
+// walker callback returns an error, the walk is aborted and the error
+// returned.
+func (graph *Graph) DepthFirstWalk(walker Walker) error {
+	seen := make(map[Vertex]uint8)
+	for elem := graph.order.Front(); elem != nil; elem = elem.Next() {
+		if vertex := elem.Value; seen[vertex] == WHITE {
+			if err := graph.depthFirstVisit(walker, seen, vertex); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fillableWalker is a walker that calls the provided functions on
+// discovery and finish of each vertex.
+type fillableWalker struct {
+	DefaultWalker
+	onDiscover, onFinish VertexWalkFunc
+}
+
+func (walker *fillableWalker) OnDiscover(parent, vertex Vertex) error {
+	if walker.onDiscover != nil {
+		return walker.onDiscover(vertex)
+	}
+	return nil
+}
+
+func (walker *fillableWalker) OnFinish(parent, vertex Vertex) error {
+	if walker.onFinish != nil {
+		return walker.onFinish(vertex)
+	}
+	return nil
+}
+
+// DoDepthFirst will process the graph in depth-first order. When a
+// vertex is discovered (seen for the first time), onDiscover will be
+// called with the vertex. When all the descendants of the vertex have
+// been processed, onFinish will be called with the vertex. Either
+// function can be nil.
+func (graph *Graph) DoDepthFirst(onDiscover, onFinish VertexWalkFunc) error {
+	walker := &fillableWalker{
+		onDiscover: onDiscover,
+		onFinish:   onFinish,
+	}
+	return graph.DepthFirstWalk(walker)
+}
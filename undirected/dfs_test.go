@@ -0,0 +1,94 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import (
+	"errors"
+	"testing"
+)
+
+// edgeWalker records the tree and back edges found in a walk.
+type edgeWalker struct {
+	DefaultWalker
+	tree, back, cross int
+}
+
+func (walker *edgeWalker) OnDiscover(parent, vertex Vertex) error {
+	if parent != nil {
+		walker.tree++
+	}
+	return nil
+}
+
+func (walker *edgeWalker) OnBackEdge(source, target Vertex) error {
+	walker.back++
+	return nil
+}
+
+func (walker *edgeWalker) OnCrossEdge(source, target Vertex) error {
+	walker.cross++
+	return nil
+}
+
+func TestDepthFirstWalk(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(3, 1)
+	graph.AddEdge(3, 4)
+	graph.AddEdge(5, 6)
+	graph.AddEdge(6, 6)
+
+	// Each edge should be either a tree edge or a back edge.
+	walker := &edgeWalker{}
+	if err := graph.DepthFirstWalk(walker); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if walker.tree != 4 || walker.back != 2 || walker.cross != 0 {
+		t.Errorf("Wrong edge classification (tree %d, back %d, cross %d)",
+			walker.tree, walker.back, walker.cross)
+	}
+
+	// Check that the discovery and finish order nest properly.
+	discover := make(map[Vertex]int)
+	finish := make(map[Vertex]int)
+	time := 0
+	graph.DoDepthFirst(func(vertex Vertex) error {
+		time++
+		discover[vertex] = time
+		return nil
+	}, func(vertex Vertex) error {
+		time++
+		finish[vertex] = time
+		return nil
+	})
+	graph.DoEdges(func(x, y Vertex) error {
+		if discover[x] > discover[y] {
+			x, y = y, x
+		}
+		if x != y && !(discover[x] < discover[y] && finish[y] < finish[x]) {
+			t.Errorf("Edge (%v,%v) not nested", x, y)
+		}
+		return nil
+	})
+}
+
+func TestDepthFirstError(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	count := 0
+	err := graph.DoDepthFirst(func(vertex Vertex) error {
+		count++
+		if count == 2 {
+			return errors.New("stop")
+		}
+		return nil
+	}, nil)
+	if err == nil || count != 2 {
+		t.Errorf("Walk not aborted (error %v, count %d)", err, count)
+	}
+}
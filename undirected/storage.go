@@ -0,0 +1,240 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+// Package undirected provides support for creating and working with
+// undirected graphs.
+package undirected
+
+import "container/list"
+
+// Vertex is a convenience declaration for a vertex of the
+// graph. There are currently no expectations on the vertices of a
+// graph: any object that can be used as key in a map can be used.
+type Vertex interface{}
+
+type adjacencyList map[Vertex]*list.List
+
+// Graph is the representation of an undirected graph. It contain all
+// the edges and vertices of the graph. Each edge is stored in the
+// adjacency lists of both its endpoints, except self-loops, which are
+// only stored once.
+//
+// The graph also keeps track of the order in which the vertices were
+// added, so that iterating over the vertices and edges, and walking
+// the graph, is done in a deterministic order.
+type Graph struct {
+	edges                  adjacencyList
+	order                  *list.List
+	position               map[Vertex]*list.Element
+	edgeCount, vertexCount int
+}
+
+// find is used to locate an element in a list by value. It will
+// return true and a pointer to the element if the element was found
+// and false and nil otherwise.
+func find(lst *list.List, value Vertex) (bool, *list.Element) {
+	for elem := lst.Front(); elem != nil; elem = elem.Next() {
+		if elem.Value == value {
+			return true, elem
+		}
+	}
+	return false, nil
+}
+
+// New will create a new, empty, undirected graph.
+func New() *Graph {
+	return &Graph{
+		edges:    make(adjacencyList),
+		order:    list.New(),
+		position: make(map[Vertex]*list.Element),
+	}
+}
+
+// AddEdge add an edge between two vertices to the graph. The vertices
+// will be added to the graph if they are not already present. The
+// function return 'true' if the edge was successfully added, and
+// 'false' if the edge already existed.
+func (graph *Graph) AddEdge(x, y Vertex) bool {
+	graph.AddVertex(x)
+	graph.AddVertex(y)
+	if found, _ := find(graph.edges[x], y); found {
+		return false
+	}
+	graph.edges[x].PushBack(y)
+	if x != y {
+		graph.edges[y].PushBack(x)
+	}
+	graph.edgeCount++
+	return true
+}
+
+// RemoveEdge will remove an edge from the graph. The vertices that
+// serve as endpoints for the edge will not be removed. The method
+// returns 'true' if the edge was successfully removed, 'false'
+// otherwise.
+func (graph *Graph) RemoveEdge(x, y Vertex) bool {
+	lst := graph.edges[x]
+	if lst == nil {
+		return false
+	}
+	found, elem := find(lst, y)
+	if !found {
+		return false
+	}
+	lst.Remove(elem)
+	if x != y {
+		_, elem = find(graph.edges[y], x)
+		graph.edges[y].Remove(elem)
+	}
+	graph.edgeCount--
+	return true
+}
+
+// AddVertex will add a vertex to the graph. The vertex will have no
+// edges. The function return 'true' if the vertex was successfully
+// added, and 'false' if the vertex already existed.
+func (graph *Graph) AddVertex(vertex Vertex) bool {
+	if graph.edges[vertex] == nil {
+		graph.edges[vertex] = list.New()
+		graph.position[vertex] = graph.order.PushBack(vertex)
+		graph.vertexCount++
+		return true
+	}
+	return false
+}
+
+// RemoveVertex will remove the vertex from the graph. Any edges
+// connecting to the vertex will also be removed.
+func (graph *Graph) RemoveVertex(vertex Vertex) bool {
+	lst := graph.edges[vertex]
+	if lst == nil {
+		return false
+	}
+	// Since each edge is stored with both endpoints, it is
+	// sufficient to visit the neighbours to remove the edges from
+	// their lists.
+	for elem := lst.Front(); elem != nil; elem = elem.Next() {
+		if elem.Value != vertex {
+			neighbours := graph.edges[elem.Value]
+			_, other := find(neighbours, vertex)
+			neighbours.Remove(other)
+		}
+		graph.edgeCount--
+	}
+	delete(graph.edges, vertex)
+	graph.order.Remove(graph.position[vertex])
+	delete(graph.position, vertex)
+	graph.vertexCount--
+	return true
+}
+
+// HasVertex check if a vertex exists in the graph. Will return 'true'
+// if the vertex exists and 'false' otherwise.
+func (graph *Graph) HasVertex(vertex Vertex) bool {
+	return graph.edges[vertex] != nil
+}
+
+// HasEdge check if an edge between two vertices exists in the
+// graph. Will return 'true' if the edge exists, and 'false'
+// otherwise.
+func (graph *Graph) HasEdge(x, y Vertex) bool {
+	if lst := graph.edges[x]; lst != nil {
+		found, _ := find(lst, y)
+		return found
+	}
+	return false
+}
+
+// Degree will return the degree of a vertex, that is, the number of
+// edges incident to the vertex. Self-loops are counted twice, as is
+// customary. The degree of a vertex not in the graph is zero.
+func (graph *Graph) Degree(vertex Vertex) int {
+	lst := graph.edges[vertex]
+	if lst == nil {
+		return 0
+	}
+	degree := lst.Len()
+	if found, _ := find(lst, vertex); found {
+		degree++
+	}
+	return degree
+}
+
+// Order will return the order of the graph, that is, the number of
+// vertices in the graph.
+func (graph *Graph) Order() int {
+	return graph.vertexCount
+}
+
+// Size will return size of the graph, that is the number of edges in
+// the graph. Each edge is only counted once.
+func (graph *Graph) Size() int {
+	return graph.edgeCount
+}
+
+// VertexWalkFunc is a function called when walking vertices of a
+// graph.
+type VertexWalkFunc func(vertex Vertex) error
+
+// DoVertices iterate over all the vertices of the graph calling
+// 'walkFn' with each vertex, in the order the vertices were added to
+// the graph. If the walk function returns an error, iteration will be
+// aborted and the error returned to the caller.
+func (graph *Graph) DoVertices(walkFn VertexWalkFunc) error {
+	for elem := graph.order.Front(); elem != nil; {
+		// Fetch the next element first, in case the walk
+		// function removes the vertex.
+		next := elem.Next()
+		if err := walkFn(elem.Value); err != nil {
+			return err
+		}
+		elem = next
+	}
+	return nil
+}
+
+// EdgeWalkFunc is a function called when walking edges of a graph.
+type EdgeWalkFunc func(x, y Vertex) error
+
+// DoEdges will iterate over all the edges of the graph calling
+// 'walkFn' with the endpoints of the edge. Each edge is visited
+// exactly once, from the endpoint that was added to the graph first,
+// and the edges are processed in the order of that endpoint and then
+// in the order they were added. If the walk function return an error,
+// iteration will be aborted and the error returned.
+func (graph *Graph) DoEdges(walkFn EdgeWalkFunc) error {
+	// An edge is only visited from the endpoint that was
+	// processed first.
+	done := make(map[Vertex]bool, graph.vertexCount)
+	for pos := graph.order.Front(); pos != nil; pos = pos.Next() {
+		vertex, edges := pos.Value, graph.edges[pos.Value]
+		for elem := edges.Front(); elem != nil; elem = elem.Next() {
+			if !done[elem.Value] {
+				if err := walkFn(vertex, elem.Value); err != nil {
+					return err
+				}
+			}
+		}
+		done[vertex] = true
+	}
+	return nil
+}
+
+// DoIncidentEdges iterate over the edges incident to a vertex,
+// calling 'walkFn' with the vertex and the neighbour at the other
+// end of the edge. If the walk function return an error, iteration
+// will be aborted and the error returned.
+func (graph *Graph) DoIncidentEdges(vertex Vertex, walkFn EdgeWalkFunc) error {
+	lst := graph.edges[vertex]
+	if lst == nil {
+		return nil
+	}
+	for elem := lst.Front(); elem != nil; elem = elem.Next() {
+		if err := walkFn(vertex, elem.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2013, Mats Kindahl. All rights reserved.
+//
+// Use of this source code is governed by a BSD license that can be
+// found in the README file.
+
+package undirected
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func checkGraphCount(t *testing.T, graph *Graph, vertices_expected, edges_expected int) {
+	vertices := graph.Order()
+	if vertices_expected != vertices {
+		t.Errorf("Wrong number of vertices (was %d, expected %d)", vertices, vertices_expected)
+	}
+	edges := graph.Size()
+	if edges_expected != edges {
+		t.Errorf("Wrong number of edges (was %d, expected %d)", edges, edges_expected)
+	}
+}
+
+func TestAddVertex(t *testing.T) {
+	graph := New()
+	for i := 0; i < 10; i++ {
+		if !graph.AddVertex(i) {
+			t.Errorf("Vertex %v cannot be added", i)
+		}
+		if graph.AddVertex(i) {
+			t.Errorf("Vertex %v should not be added", i)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if !graph.HasVertex(i) {
+			t.Errorf("Vertex %v missing", i)
+		}
+		if graph.HasVertex(i + 10) {
+			t.Errorf("Extreneous vertex %v", i+10)
+		}
+	}
+
+	count := 0
+	err := graph.DoVertices(func(Vertex) error {
+		if count > 5 {
+			return errors.New("count > 5")
+		}
+		count++
+		return nil
+	})
+	if err == nil || err.Error() != "count > 5" {
+		t.Errorf("Incorrect error returned: %v", err)
+	}
+}
+
+func TestAddEdge(t *testing.T) {
+	graph := New()
+	for i := 0; i < 10; i++ {
+		for j := 10; j < 20; j++ {
+			if !graph.AddEdge(i, j) {
+				t.Errorf("Edge (%v,%v) cannot be added", i, j)
+			}
+			if graph.AddEdge(j, i) {
+				t.Errorf("Reversed edge (%v,%v) can be added", j, i)
+			}
+		}
+	}
+	checkGraphCount(t, graph, 20, 100)
+
+	for i := 0; i < 10; i++ {
+		for j := 10; j < 20; j++ {
+			if !graph.HasEdge(i, j) || !graph.HasEdge(j, i) {
+				t.Errorf("Edge (%v,%v) missing", i, j)
+			}
+			if graph.HasEdge(i+10, j) {
+				t.Errorf("Edge (%v,%v) extreneous", i+10, j)
+			}
+		}
+	}
+
+	// Check that each edge is processed exactly once.
+	check := make(map[[2]int]int)
+	graph.DoEdges(func(x, y Vertex) error {
+		if x.(int) > y.(int) {
+			x, y = y, x
+		}
+		check[[2]int{x.(int), y.(int)}]++
+		return nil
+	})
+	if len(check) != 100 {
+		t.Errorf("Wrong number of edges processed (was %d, expected %d)", len(check), 100)
+	}
+	for edge, count := range check {
+		if count != 1 {
+			t.Errorf("Edge %v processed %d times", edge, count)
+		}
+	}
+}
+
+func TestDegree(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(1, 3)
+	graph.AddEdge(1, 1)
+	graph.AddVertex(4)
+	for vertex, expected := range map[int]int{1: 4, 2: 1, 3: 1, 4: 0, 5: 0} {
+		if degree := graph.Degree(vertex); degree != expected {
+			t.Errorf("Wrong degree of %v (was %d, expected %d)", vertex, degree, expected)
+		}
+	}
+	checkGraphCount(t, graph, 4, 3)
+
+	count := 0
+	graph.DoEdges(func(x, y Vertex) error {
+		count++
+		return nil
+	})
+	if count != 3 {
+		t.Errorf("Wrong number of edges processed (was %d, expected %d)", count, 3)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	graph := New()
+	graph.AddEdge(1, 2)
+	graph.AddEdge(1, 3)
+	graph.AddEdge(3, 4)
+	graph.AddEdge(2, 4)
+	graph.AddEdge(4, 4)
+	checkGraphCount(t, graph, 4, 5)
+
+	if !graph.RemoveEdge(2, 1) {
+		t.Errorf("Edge (%d,%d) not removed", 2, 1)
+	}
+	if graph.HasEdge(1, 2) || graph.HasEdge(2, 1) {
+		t.Errorf("Edge (%d,%d) present", 1, 2)
+	}
+	if graph.RemoveEdge(1, 2) {
+		t.Errorf("Edge (%d,%d) removed twice", 1, 2)
+	}
+	checkGraphCount(t, graph, 4, 4)
+
+	if !graph.RemoveVertex(4) {
+		t.Errorf("Vertex %d not removed", 4)
+	}
+	checkGraphCount(t, graph, 3, 1)
+	if graph.HasEdge(3, 4) || graph.HasEdge(2, 4) {
+		t.Errorf("Edges of vertex %d present", 4)
+	}
+	if graph.Degree(2) != 0 || graph.Degree(3) != 1 {
+		t.Errorf("Wrong degrees after removing vertex %d", 4)
+	}
+}
+
+func TestVertexOrder(t *testing.T) {
+	graph := New()
+	for _, vertex := range []int{5, 3, 8, 1, 9} {
+		graph.AddVertex(vertex)
+	}
+	graph.AddEdge(2, 5)
+	graph.AddEdge(9, 3)
+	graph.AddEdge(1, 9)
+	graph.RemoveVertex(8)
+	graph.AddVertex(8)
+
+	var order []Vertex
+	graph.DoVertices(func(vertex Vertex) error {
+		order = append(order, vertex)
+		return nil
+	})
+	if fmt.Sprint(order) != "[5 3 1 9 2 8]" {
+		t.Errorf("Vertices not in insertion order: %v", order)
+	}
+
+	var edges []string
+	graph.DoEdges(func(x, y Vertex) error {
+		edges = append(edges, fmt.Sprintf("%v-%v", x, y))
+		return nil
+	})
+	if fmt.Sprint(edges) != "[5-2 3-9 1-9]" {
+		t.Errorf("Edges not in insertion order: %v", edges)
+	}
+
+	walks := map[string]func(onDiscover, onFinish VertexWalkFunc) error{
+		"depth-first":   graph.DoDepthFirst,
+		"breadth-first": graph.DoBreadthFirstWalk,
+	}
+	for name, walk := range walks {
+		order = nil
+		walk(func(vertex Vertex) error {
+			order = append(order, vertex)
+			return nil
+		}, nil)
+		if fmt.Sprint(order) != "[5 2 3 9 1 8]" {
+			t.Errorf("%s walk not in insertion order: %v", name, order)
+		}
+	}
+}